@@ -0,0 +1,150 @@
+package CoronaAPI
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type PolicyImpact struct {
+	Country      string
+	Scope        string
+	Best_lag     int // days from stringency to case growth with the most negative correlation
+	Best_r       float64
+	Correlations []LagCorrelation
+	Metadata     *Metadata `json:",omitempty"` // for stringency and cases
+}
+
+type LagCorrelation struct {
+	Lag int
+	R   float64 // pearson correlation between stringency and case growth lag days later
+	N   int
+}
+
+const (
+	defaultMaxLag = 21
+	maxMaxLag     = 60
+	growthDays    = 7 // case growth is the change in new cases over a week
+)
+
+// http://localhost:8080/corona/v1/analysis/{:country_name}/policy-impact{?scope=begin_date-end_date&maxLag=days}
+func HandlePolicyImpact(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName, startDate, endDate, err := getUrlData("country_name", r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		maxLag := defaultMaxLag
+		if maxLagQuery := r.URL.Query().Get("maxLag"); maxLagQuery != "" {
+			var err error
+			maxLag, err = strconv.Atoi(maxLagQuery)
+			if err != nil || maxLag < 0 || maxLag > maxMaxLag {
+				writeError(w, r, errors.New("Invalid maxLag, should be a number between 0 and "+strconv.Itoa(maxMaxLag)))
+				return
+			}
+		}
+
+		response, err := getPolicyImpact(countryName, startDate, endDate, maxLag)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// correlates stringency with case growth lag days later, for every lag up to maxLag
+func getPolicyImpact(countryName string, startDate string, endDate string, maxLag int) (PolicyImpact, error) {
+	var response PolicyImpact
+	stringency, err := getStringencyTimeSeries(countryName, startDate, endDate, "day")
+	if err != nil {
+		return response, err
+	}
+	cases, err := getCasesTimeSeries(countryName, "", "", resolveExact, "day")
+	if err != nil {
+		return response, err
+	}
+	growth := getCaseGrowth(cases.Series)
+
+	response.Country = countryName
+	response.Scope = stringency.Scope
+	response.Correlations = []LagCorrelation{}
+	best := math.Inf(1)
+	for lag := 0; lag <= maxLag; lag++ {
+		var xs, ys []float64
+		for _, point := range stringency.Series {
+			date, err := time.Parse(dateLayout, point.Date)
+			if err != nil {
+				continue
+			}
+			if g, ok := growth[date.AddDate(0, 0, lag).Format(dateLayout)]; ok {
+				xs = append(xs, point.Stringency)
+				ys = append(ys, g)
+			}
+		}
+		r, ok := getPearsonCorrelation(xs, ys)
+		if !ok {
+			continue
+		}
+		response.Correlations = append(response.Correlations, LagCorrelation{Lag: lag, R: roundTwoDecimals(r), N: len(xs)})
+		if r < best {
+			best = r
+			response.Best_lag = lag
+			response.Best_r = roundTwoDecimals(r)
+		}
+	}
+	if len(response.Correlations) == 0 {
+		return response, errors.New("Not enough overlapping stringency and case data for " + countryName)
+	}
+	response.Metadata = mergeMetadata(stringency.Metadata, cases.Metadata)
+	return response, nil
+}
+
+// gets the growth of new cases per date, as log of this week's new cases over last week's
+func getCaseGrowth(daily []CasesPoint) map[string]float64 {
+	growth := map[string]float64{}
+	for i := 2*growthDays - 1; i < len(daily); i++ {
+		var thisWeek, lastWeek float64
+		for day := 0; day < growthDays; day++ {
+			thisWeek += float64(daily[i-day].New_cases)
+			lastWeek += float64(daily[i-growthDays-day].New_cases)
+		}
+		if thisWeek > 0 && lastWeek > 0 {
+			growth[daily[i].Date] = math.Log(thisWeek / lastWeek)
+		}
+	}
+	return growth
+}
+
+// gets the pearson correlation of xs and ys, false if it's not defined
+func getPearsonCorrelation(xs []float64, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	if len(xs) < 3 {
+		return 0, false
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= n
+	meanY /= n
+	var covariance, varianceX, varianceY float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		varianceX += (xs[i] - meanX) * (xs[i] - meanX)
+		varianceY += (ys[i] - meanY) * (ys[i] - meanY)
+	}
+	if varianceX == 0 || varianceY == 0 { // a flat series has no correlation
+		return 0, false
+	}
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
@@ -0,0 +1,164 @@
+package CoronaAPI
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type AnomalyReport struct {
+	Country   string
+	Scope     string
+	Anomalies []Anomaly
+	Metadata  *Metadata `json:",omitempty"`
+}
+
+type Anomaly struct {
+	Date      string
+	Type      string // outlier, negative or stale
+	New_cases int
+	Score     float64 `json:",omitempty"` // robust z-score of outliers
+	Days      int     `json:",omitempty"` // length of stale periods
+}
+
+const (
+	anomalyWindow       = 28  // days before a day that it is compared to
+	anomalyThreshold    = 3.5 // robust z-score above this is an outlier
+	staleDays           = 7   // days in a row without new cases to be stale
+	madToStandardFactor = 0.6745
+)
+
+// http://localhost:8080/corona/v1/country/{:country_name}/anomalies{?scope=begin_date-end_date}
+func HandleAnomalies(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName, startDate, endDate, err := getUrlData("country_name", r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response, err := getAnomalyReport(countryName, startDate, endDate)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// gets anomalies in the daily new cases of a country, the whole history is used so the first days in scope have a window
+func getAnomalyReport(countryName string, startDate string, endDate string) (AnomalyReport, error) {
+	var response AnomalyReport
+	series, err := getCasesTimeSeries(countryName, "", "", resolveExact, "day")
+	if err != nil {
+		return response, err
+	}
+
+	response.Country = countryName
+	response.Scope = getScopeName(startDate, endDate)
+	response.Anomalies = []Anomaly{}
+	for _, anomaly := range detectAnomalies(series.Series) {
+		if startDate != "" && (anomaly.Date < startDate || anomaly.Date > endDate) {
+			continue
+		}
+		response.Anomalies = append(response.Anomalies, anomaly)
+	}
+	response.Metadata = series.Metadata
+	return response, nil
+}
+
+// finds outliers, negative corrections and stale periods in daily data, sorted by date
+func detectAnomalies(daily []CasesPoint) []Anomaly {
+	var anomalies []Anomaly
+	staleStart := -1
+	hasCases := false
+	for i, point := range daily {
+		if point.New_cases < 0 {
+			anomalies = append(anomalies, Anomaly{Date: point.Date, Type: "negative", New_cases: point.New_cases})
+		} else if i >= anomalyWindow {
+			if score, ok := getRobustZScore(daily[i-anomalyWindow:i], point.New_cases); ok && score > anomalyThreshold {
+				anomalies = append(anomalies, Anomaly{Date: point.Date, Type: "outlier", New_cases: point.New_cases, Score: math.Round(score*100) / 100})
+			}
+		}
+
+		// stale periods only count after the first case, before that zero is real
+		if point.New_cases == 0 && hasCases {
+			if staleStart == -1 {
+				staleStart = i
+			}
+		} else {
+			if staleStart != -1 && i-staleStart >= staleDays {
+				anomalies = append(anomalies, Anomaly{Date: daily[staleStart].Date, Type: "stale", Days: i - staleStart})
+			}
+			staleStart = -1
+		}
+		if point.New_cases > 0 {
+			hasCases = true
+		}
+	}
+	if staleStart != -1 && len(daily)-staleStart >= staleDays {
+		anomalies = append(anomalies, Anomaly{Date: daily[staleStart].Date, Type: "stale", Days: len(daily) - staleStart})
+	}
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Date < anomalies[j].Date })
+	return anomalies
+}
+
+// gets the robust z-score of value compared to window, using median and median absolute deviation
+func getRobustZScore(window []CasesPoint, value int) (float64, bool) {
+	var values []float64
+	for _, point := range window {
+		values = append(values, float64(point.New_cases))
+	}
+	median := getMedian(values)
+	var deviations []float64
+	for _, v := range values {
+		deviations = append(deviations, math.Abs(v-median))
+	}
+	mad := getMedian(deviations)
+	if mad == 0 { // flat window, the score is not defined
+		return 0, false
+	}
+	return madToStandardFactor * (float64(value) - median) / mad, true
+}
+
+// gets the median of values
+func getMedian(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// checks if the newest day of a country is an anomaly, used to hold back ON_CHANGE notifications
+func isLatestDayAnomalous(countryName string) (bool, error) {
+	series, err := getCasesTimeSeries(countryName, "", "", resolveExact, "day")
+	if err != nil || len(series.Series) == 0 {
+		return false, err
+	}
+	latest := series.Series[len(series.Series)-1].Date
+	for _, anomaly := range detectAnomalies(series.Series) {
+		if anomaly.Date == latest || (anomaly.Type == "stale" && isInStalePeriod(anomaly, latest)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checks if date is inside a stale period
+func isInStalePeriod(anomaly Anomaly, date string) bool {
+	start, err := time.Parse(dateLayout, anomaly.Date)
+	if err != nil {
+		return false
+	}
+	end := start.AddDate(0, 0, anomaly.Days-1).Format(dateLayout)
+	return date >= anomaly.Date && date <= end
+}
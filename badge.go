@@ -0,0 +1,168 @@
+package CoronaAPI
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// colours of the badge value, from below the first threshold to above the last
+var badgeColours = []string{"#4c1", "#dfb317", "#e05d44"}
+
+// thresholds used when not in url or in BADGE_THRESHOLDS_{METRIC}
+var defaultBadgeThresholds = map[string][]float64{
+	"incidence":  {50, 200},
+	"stringency": {40, 70},
+}
+
+// number of days incidence is counted over
+const incidenceDays = 14
+
+// colour of a badge without data
+const noDataColour = "#9f9f9f"
+
+// http://localhost:8080/corona/v1/badge/{:country_name}.svg{?metric=incidence|stringency|confirmed&thresholds=low,high}
+func HandleBadge(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName := strings.Title(strings.ToLower(pathParam(r, "country_name")))
+		metric := r.URL.Query().Get("metric")
+		if metric == "" {
+			metric = "incidence"
+		}
+		thresholds, err := getBadgeThresholds(r, metric)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		label, value, number, metadata, err := getBadgeValue(countryName, metric)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		http.Header.Add(w.Header(), "content-type", "image/svg+xml")
+		// data changes daily, so dashboards can keep the badge for an hour
+		http.Header.Add(w.Header(), "cache-control", "public, max-age=3600")
+		w.Write([]byte(renderBadge(countryName+" "+label, value, getBadgeColour(number, thresholds), metadata)))
+		return
+	default:
+		return
+	}
+}
+
+// gets thresholds from ?thresholds, or BADGE_THRESHOLDS_{METRIC}, or the defaults
+func getBadgeThresholds(r *http.Request, metric string) ([]float64, error) {
+	thresholdsQuery := r.URL.Query().Get("thresholds")
+	if thresholdsQuery == "" {
+		thresholdsQuery = os.Getenv("BADGE_THRESHOLDS_" + strings.ToUpper(metric))
+	}
+	if thresholdsQuery == "" {
+		return defaultBadgeThresholds[metric], nil
+	}
+	var thresholds []float64
+	for _, part := range strings.Split(thresholdsQuery, ",") {
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errors.New("Invalid thresholds '" + thresholdsQuery + "', should be numbers separated by comma, like 50,200")
+		}
+		if len(thresholds) > 0 && threshold <= thresholds[len(thresholds)-1] {
+			return nil, errors.New("Invalid thresholds '" + thresholdsQuery + "', should be increasing")
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	if len(thresholds) > len(badgeColours)-1 {
+		return nil, errors.New("Invalid thresholds '" + thresholdsQuery + "', can't have more than " + strconv.Itoa(len(badgeColours)-1))
+	}
+	return thresholds, nil
+}
+
+// gets the label and value to show on the badge, the number used for colour and the metadata of the data
+func getBadgeValue(countryName string, metric string) (string, string, float64, *Metadata, error) {
+	switch metric {
+	case "incidence":
+		// new cases the last 14 days per 100 000 inhabitants
+		end := time.Now().AddDate(0, 0, -1)
+		confirmedData, err := getConfirmedData(countryName)
+		if err != nil {
+			return "", "", 0, nil, err
+		}
+		// with data older than the 14 days there is nothing to count, which is not the same as 0
+		latestDate := getLatestDate(getDates(confirmedData))
+		start := end.AddDate(0, 0, -incidenceDays+1)
+		if latestDate < start.Format(dateLayout) {
+			return "incidence", "no data", math.NaN(), newMetadata(latestDate, confirmedData.source), nil
+		}
+		cases, err := getCases(countryName, start.Format(dateLayout), end.Format(dateLayout), resolveClamp)
+		if err != nil {
+			return "", "", 0, nil, err
+		}
+		population, _ := confirmedData.All["population"].(float64)
+		if population == 0 {
+			return "", "", 0, nil, errors.New("Missing population for " + countryName)
+		}
+		incidence := float64(cases.Confirmed) / population * 100000
+		return "incidence", fmt.Sprintf("%.0f/100k", incidence), incidence, cases.Metadata, nil
+	case "stringency":
+		end := time.Now().AddDate(0, 0, -1)
+		series, err := getStringencyTimeSeries(countryName, end.AddDate(0, 0, -30).Format(dateLayout), end.Format(dateLayout), "day")
+		if err != nil {
+			return "", "", 0, nil, err
+		}
+		latest := series.Series[len(series.Series)-1] // data is a few days behind, so uses the newest
+		return "stringency", fmt.Sprintf("%.1f", latest.Stringency), latest.Stringency, series.Metadata, nil
+	case "confirmed":
+		cases, err := getCases(countryName, "", "", resolveExact)
+		if err != nil {
+			return "", "", 0, nil, err
+		}
+		return "confirmed", formatAxisValue(float64(cases.Confirmed), 0.01), float64(cases.Confirmed), cases.Metadata, nil
+	}
+	return "", "", 0, nil, errors.New("Invalid metric '" + metric + "', should be one of: incidence, stringency, confirmed")
+}
+
+// gets the colour for value, blue if there are no thresholds and grey if there is no value (NaN)
+func getBadgeColour(value float64, thresholds []float64) string {
+	if math.IsNaN(value) {
+		return noDataColour
+	}
+	if len(thresholds) == 0 {
+		return "#007ec6"
+	}
+	for i, threshold := range thresholds {
+		if value < threshold {
+			return badgeColours[i]
+		}
+	}
+	return badgeColours[len(thresholds)]
+}
+
+// draws a badge with label on grey and value on colour
+func renderBadge(label string, value string, colour string, metadata *Metadata) string {
+	const charWidth, padding = 6.5, 10.0
+	labelWidth := float64(utf8.RuneCountInString(label))*charWidth + padding // counts characters, not bytes
+	valueWidth := float64(utf8.RuneCountInString(value))*charWidth + padding
+	width := labelWidth + valueWidth
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="20" role="img" aria-label="%s: %s">`, width, html.EscapeString(label), html.EscapeString(value))
+	fmt.Fprintf(&svg, `<title>%s: %s</title>`, html.EscapeString(label), html.EscapeString(value))
+	svg.WriteString(renderSvgMetadata(metadata))
+	fmt.Fprintf(&svg, `<rect width="%.0f" height="20" rx="3" fill="#555"/>`, width)
+	fmt.Fprintf(&svg, `<rect x="%.0f" width="%.0f" height="20" rx="3" fill="%s"/>`, labelWidth, valueWidth, colour)
+	fmt.Fprintf(&svg, `<rect x="%.0f" width="4" height="20" fill="%s"/>`, labelWidth, colour) // square corner between the parts
+	fmt.Fprintf(&svg, `<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,sans-serif" font-size="11">`)
+	fmt.Fprintf(&svg, `<text x="%.1f" y="14">%s</text>`, labelWidth/2, html.EscapeString(label))
+	fmt.Fprintf(&svg, `<text x="%.1f" y="14">%s</text>`, labelWidth+valueWidth/2, html.EscapeString(value))
+	svg.WriteString(`</g></svg>`)
+	return svg.String()
+}
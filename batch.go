@@ -0,0 +1,105 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type BatchQuery struct {
+	Type        string `json:"type"` // cases, policy, cases_timeseries or policy_timeseries
+	Country     string `json:"country"`
+	Scope       string `json:"scope"`
+	Granularity string `json:"granularity"`
+	Resolve     string `json:"resolve"`
+}
+
+type BatchResult struct {
+	Index  int         `json:"index"`
+	Status int         `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+// max number of queries in one batch
+const maxBatchSize = 500
+
+// http://localhost:8080/corona/v1/batch
+func HandleBatch(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+
+	case http.MethodPost:
+		http.Header.Add(w.Header(), "content-type", "application/json")
+		var queries []BatchQuery
+		err := json.NewDecoder(r.Body).Decode(&queries) // gets data from body
+		if err != nil {
+			writeError(w, r, fmt.Errorf("Something went wrong: %w", err))
+			return
+		}
+		if len(queries) == 0 || len(queries) > maxBatchSize {
+			writeError(w, r, errors.New("A batch should have between 1 and "+strconv.Itoa(maxBatchSize)+" queries"))
+			return
+		}
+
+		// runs the queries at the same time, the upstream cache is shared so repeated countries are only fetched once
+		results := make([]BatchResult, len(queries))
+		fetchConcurrently(len(queries), func(i int) {
+			results[i].Index = i
+			result, err := runBatchQuery(queries[i])
+			if err != nil {
+				results[i].Status = getApiError(err).Status // like 422 for a scope, 404 for a country or 5xx if an extern api fails
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Status = http.StatusOK
+			results[i].Result = result
+		})
+		json.NewEncoder(w).Encode(results)
+		return
+	default:
+		return
+	}
+}
+
+// runs a single query in a batch
+func runBatchQuery(query BatchQuery) (interface{}, error) {
+	if query.Country == "" {
+		return nil, errors.New("Missing or invalid country data")
+	}
+	countryName := strings.Title(strings.ToLower(query.Country))
+	var startDate, endDate = "", ""
+	if query.Scope != "" {
+		var err error
+		startDate, endDate, err = parseScope(query.Scope, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	resolution, err := parseDateResolution(query.Resolve)
+	if err != nil {
+		return nil, err
+	}
+	granularity, err := parseGranularity(query.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	switch query.Type {
+	case "cases":
+		return getCases(countryName, startDate, endDate, resolution)
+	case "policy":
+		if startDate == "" {
+			return nil, errors.New("policy queries need a scope")
+		}
+		return getStringencyTrends(countryName, startDate, endDate)
+	case "cases_timeseries":
+		return getCasesTimeSeries(countryName, startDate, endDate, resolution, granularity)
+	case "policy_timeseries":
+		return getStringencyTimeSeries(countryName, startDate, endDate, granularity)
+	}
+	return nil, errors.New("Invalid type '" + query.Type + "', should be one of: cases, policy, cases_timeseries, policy_timeseries")
+}
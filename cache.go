@@ -0,0 +1,144 @@
+package CoronaAPI
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type cachedResponse struct {
+	body    []byte
+	fetched time.Time
+}
+
+// how long a response from an extern api is kept before it is fetched again
+const upstreamCacheDuration = time.Hour
+
+// error when an extern api responds with something else than 200 OK
+type statusCodeError struct {
+	StatusCode int
+}
+
+func (e statusCodeError) Error() string {
+	return "status code " + strconv.Itoa(e.StatusCode)
+}
+
+// how long a request to an extern api may take when UPSTREAM_TIMEOUT is not set
+const defaultUpstreamTimeout = 20 * time.Second
+
+// client for the extern apis, with the timeout in UPSTREAM_TIMEOUT (like 30s) or defaultUpstreamTimeout
+var upstreamClient = &http.Client{Timeout: getUpstreamTimeout()}
+
+// gets the timeout for requests to the extern apis from UPSTREAM_TIMEOUT, 0 is no timeout
+// defaultUpstreamTimeout if not set or invalid
+func getUpstreamTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("UPSTREAM_TIMEOUT"))
+	if err != nil || timeout < 0 {
+		return defaultUpstreamTimeout
+	}
+	return timeout
+}
+
+// most responses kept in upstreamCache, the urls have country names and dates from clients so there is no end to them
+const maxUpstreamCacheEntries = 10000
+
+var upstreamCache = map[string]cachedResponse{}
+var upstreamCacheMutex sync.Mutex
+var upstreamCachePruned time.Time
+
+// a request to an extern api that is being made, others asking for the same url wait for it instead of making their own
+type upstreamCall struct {
+	done   chan struct{} // closed when the request is done
+	body   []byte
+	source Source
+	err    error
+}
+
+var upstreamCalls = map[string]*upstreamCall{} // guarded by upstreamCacheMutex
+
+// gets the body of a get request to url, uses the cached body if it's not too old
+func getCachedBody(url string) ([]byte, error) {
+	body, _, err := getCachedResponse(url)
+	return body, err
+}
+
+// gets the body of a get request to url and where it came from, uses the cached body if it's not too old
+// requests for a url that is already being fetched wait for that request
+func getCachedResponse(url string) ([]byte, Source, error) {
+	upstreamCacheMutex.Lock()
+	cached, ok := upstreamCache[url]
+	if ok && time.Since(cached.fetched) < upstreamCacheDuration {
+		upstreamCacheMutex.Unlock()
+		return cached.body, Source{Name: getSourceName(url), Url: url, Fetched: cached.fetched, Cached: true}, nil
+	}
+	if call, ok := upstreamCalls[url]; ok {
+		upstreamCacheMutex.Unlock()
+		<-call.done
+		source := call.source
+		source.Cached = call.err == nil // this request did not make its own
+		return call.body, source, call.err
+	}
+	call := &upstreamCall{done: make(chan struct{})}
+	upstreamCalls[url] = call
+	upstreamCacheMutex.Unlock()
+
+	call.body, call.source, call.err = fetchUpstream(url)
+
+	upstreamCacheMutex.Lock()
+	delete(upstreamCalls, url)
+	if call.err == nil { // only successful responses are cached
+		pruneUpstreamCache()
+		upstreamCache[url] = cachedResponse{body: call.body, fetched: call.source.Fetched}
+	}
+	upstreamCacheMutex.Unlock()
+	close(call.done)
+	return call.body, call.source, call.err
+}
+
+// removes expired responses from upstreamCache, at most once per upstreamCacheDuration unless it's full,
+// and the oldest tenth if it's still full, upstreamCacheMutex must be held
+func pruneUpstreamCache() {
+	if time.Since(upstreamCachePruned) < upstreamCacheDuration && len(upstreamCache) < maxUpstreamCacheEntries {
+		return
+	}
+	upstreamCachePruned = time.Now()
+	for url, cached := range upstreamCache {
+		if time.Since(cached.fetched) >= upstreamCacheDuration {
+			delete(upstreamCache, url)
+		}
+	}
+	if len(upstreamCache) < maxUpstreamCacheEntries {
+		return
+	}
+	var urls []string
+	for url := range upstreamCache {
+		urls = append(urls, url)
+	}
+	sort.Slice(urls, func(i, j int) bool { return upstreamCache[urls[i]].fetched.Before(upstreamCache[urls[j]].fetched) })
+	for _, url := range urls[:len(urls)-maxUpstreamCacheEntries*9/10] {
+		delete(upstreamCache, url)
+	}
+}
+
+// makes a get request to url
+func fetchUpstream(url string) ([]byte, Source, error) {
+	source := Source{Name: getSourceName(url), Url: url}
+	resp, err := upstreamClient.Get(url)
+	if err != nil {
+		return nil, source, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, source, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, source, statusCodeError{StatusCode: resp.StatusCode}
+	}
+	source.Fetched = time.Now()
+	return body, source, nil
+}
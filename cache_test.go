@@ -0,0 +1,120 @@
+package CoronaAPI
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serves the extern apis from handler during a test, with an empty upstream cache
+// requests keep their path and query, so the handler sees them as the extern api would
+func useTestUpstream(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	client, cache := upstreamClient, upstreamCache
+	upstreamClient = &http.Client{Transport: testUpstreamTransport{server.Listener.Addr().String()}}
+	upstreamCache = map[string]cachedResponse{}
+	t.Cleanup(func() {
+		server.Close()
+		upstreamClient, upstreamCache = client, cache
+	})
+}
+
+// sends every request to the test server
+type testUpstreamTransport struct {
+	host string
+}
+
+func (transport testUpstreamTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = "http"
+	r.URL.Host = transport.host
+	r.Host = ""
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestCachedResponseFetchesOnceAtTheSameTime(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	useTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Write([]byte("body"))
+	})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	bodies := make([]string, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, _, err := getCachedResponse("https://covid-api.mmediagroup.fr/v1/cases")
+			bodies[i], errs[i] = string(body), err
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond) // lets every caller get to the cache
+	close(release)
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("got %d requests upstream, want 1", requests)
+	}
+	for i := range bodies {
+		if bodies[i] != "body" || errs[i] != nil {
+			t.Errorf("caller %d: got %q %v, want the body", i, bodies[i], errs[i])
+		}
+	}
+}
+
+func TestUpstreamCacheIsPruned(t *testing.T) {
+	useTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	})
+	now := time.Now()
+	upstreamCache["expired"] = cachedResponse{fetched: now.Add(-2 * upstreamCacheDuration)}
+	for i := 0; i < maxUpstreamCacheEntries; i++ {
+		upstreamCache[strconv.Itoa(i)] = cachedResponse{fetched: now.Add(time.Duration(i-maxUpstreamCacheEntries) * time.Millisecond)}
+	}
+
+	if _, _, err := getCachedResponse("https://covid-api.mmediagroup.fr/v1/cases"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := upstreamCache["expired"]; ok {
+		t.Error("expired response was kept")
+	}
+	if _, ok := upstreamCache["0"]; ok {
+		t.Error("oldest response was kept in a full cache")
+	}
+	if _, ok := upstreamCache["https://covid-api.mmediagroup.fr/v1/cases"]; !ok {
+		t.Error("new response was not cached")
+	}
+	if len(upstreamCache) > maxUpstreamCacheEntries {
+		t.Errorf("got %d cached responses, want at most %d", len(upstreamCache), maxUpstreamCacheEntries)
+	}
+}
+
+func TestUpstreamTimeout(t *testing.T) {
+	tests := []struct {
+		env     string
+		timeout time.Duration
+	}{
+		{"", defaultUpstreamTimeout},
+		{"garbage", defaultUpstreamTimeout},
+		{"-5s", defaultUpstreamTimeout},
+		{"30s", 30 * time.Second},
+		{"0", 0},
+	}
+	for _, test := range tests {
+		os.Setenv("UPSTREAM_TIMEOUT", test.env)
+		if timeout := getUpstreamTimeout(); timeout != test.timeout {
+			t.Errorf("UPSTREAM_TIMEOUT=%q: got %v, want %v", test.env, timeout, test.timeout)
+		}
+	}
+	os.Unsetenv("UPSTREAM_TIMEOUT")
+}
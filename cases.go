@@ -0,0 +1,218 @@
+package CoronaAPI
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+)
+
+// policies for which date to use when a date in the scope is missing in the data
+const (
+	resolveExact    = "exact"    // the date must be in the data
+	resolvePrevious = "previous" // the closest date before
+	resolveNext     = "next"     // the closest date after
+	resolveClamp    = "clamp"    // the first or last date if outside the data, else the closest date before
+)
+
+// gets the newest date in the data, dates are YYYY-MM-DD so they sort in date order
+func getLatestDate(dates map[string]interface{}) string {
+	latestDate := ""
+	for date := range dates {
+		if date > latestDate {
+			latestDate = date
+		}
+	}
+	return latestDate
+}
+
+// gets the value of a date, returns error if the date is not in the data
+func getDateValue(dates map[string]interface{}, date string) (float64, error) {
+	value, ok := dates[date].(float64)
+	if !ok {
+		return 0, newUnprocessableError("date_without_data", "No data on "+date+". Use ?resolve=previous, next or clamp to use the closest date with data")
+	}
+	return value, nil
+}
+
+// gets the date resolution policy from ?resolve, or from DATE_RESOLUTION if not in url
+func getDateResolution(r *http.Request) (string, error) {
+	return parseDateResolution(r.URL.Query().Get("resolve"))
+}
+
+// checks a date resolution policy, DATE_RESOLUTION or exact is used if it's empty
+func parseDateResolution(resolution string) (string, error) {
+	if resolution == "" {
+		resolution = os.Getenv("DATE_RESOLUTION")
+	}
+	switch resolution {
+	case "":
+		return resolveExact, nil
+	case resolveExact, resolvePrevious, resolveNext, resolveClamp:
+		return resolution, nil
+	}
+	return "", errors.New("Invalid resolve '" + resolution + "', should be one of: exact, previous, next, clamp")
+}
+
+// finds the date in the data to use for date, based on the resolution policy
+func resolveDate(dates map[string]interface{}, date string, resolution string) (string, error) {
+	if _, ok := dates[date]; ok || resolution == resolveExact || resolution == "" {
+		return date, nil // missing dates in exact is reported by getDateValue
+	}
+	sortedDates := getSortedDates(dates)
+	if len(sortedDates) == 0 {
+		return "", errors.New("No dates in the data")
+	}
+	i := sort.SearchStrings(sortedDates, date) // index of the first date after date
+
+	switch resolution {
+	case resolvePrevious:
+		if i == 0 {
+			return "", newUnprocessableError("date_without_data", "No data on or before "+date+", first date with data is "+sortedDates[0])
+		}
+		return sortedDates[i-1], nil
+	case resolveNext:
+		if i == len(sortedDates) {
+			return "", newUnprocessableError("date_without_data", "No data on or after "+date+", last date with data is "+sortedDates[len(sortedDates)-1])
+		}
+		return sortedDates[i], nil
+	}
+	// clamp
+	if i == 0 {
+		return sortedDates[0], nil
+	}
+	return sortedDates[i-1], nil
+}
+
+// finds the start and end date in the data to use for the scope
+func resolveScope(dates map[string]interface{}, startDate string, endDate string, resolution string) (string, string, error) {
+	if startDate == "" { // if not using scope
+		return "", "", nil
+	}
+	if resolution == resolveClamp { // clamping a scope that misses the data would make both ends the same date
+		sortedDates := getSortedDates(dates)
+		if len(sortedDates) > 0 && (endDate < sortedDates[0] || startDate > sortedDates[len(sortedDates)-1]) {
+			return "", "", newUnprocessableError("date_without_data", "No data from "+startDate+" to "+endDate+
+				", the data is from "+sortedDates[0]+" to "+sortedDates[len(sortedDates)-1])
+		}
+	}
+	resolvedStart, err := resolveDate(dates, startDate, resolution)
+	if err != nil {
+		return "", "", err
+	}
+	resolvedEnd, err := resolveDate(dates, endDate, resolution)
+	if err != nil {
+		return "", "", err
+	}
+	return resolvedStart, resolvedEnd, nil
+}
+
+// gets all dates in the data, oldest first
+func getSortedDates(dates map[string]interface{}) []string {
+	var sortedDates []string
+	for k := range dates {
+		sortedDates = append(sortedDates, k)
+	}
+	sort.Strings(sortedDates) // dates are YYYY-MM-DD, so they sort by time
+	return sortedDates
+}
+
+// gets the dates map from mmediagroup data
+func getDates(data Mmediagroup) map[string]interface{} {
+	dates, _ := data.All["dates"].(map[string]interface{})
+	return dates
+}
+
+// gets the increase from start to end date, the cases on the start date are counted as in the time series,
+// or the newest value if not using scope
+func getScopedValue(dates map[string]interface{}, startDate string, endDate string, latestDate string) (float64, error) {
+	if startDate == "" { // if not using scope
+		value, _ := dates[latestDate].(float64)
+		return value, nil
+	}
+	if _, err := getDateValue(dates, startDate); err != nil {
+		return 0, err
+	}
+	endValue, err := getDateValue(dates, endDate)
+	if err != nil {
+		return 0, err
+	}
+	return endValue - getValueBefore(dates, startDate), nil
+}
+
+// gets the value on the newest date with data before date, 0 if there is none
+func getValueBefore(dates map[string]interface{}, date string) float64 {
+	before := ""
+	for d := range dates {
+		if d < date && d > before {
+			before = d
+		}
+	}
+	value, _ := dates[before].(float64)
+	return value
+}
+
+// gets value in percent of population, rounded down to two decimals
+func getPopulationPercentage(value float64, population float64) float64 {
+	if population == 0 {
+		return 0
+	}
+	percentPlaceholder := 100 / (population / value)
+	return math.Floor(percentPlaceholder*100) / 100
+}
+
+// gets the scope as it is shown in responses
+func getScopeName(startDate string, endDate string) string {
+	if startDate == "" {
+		return "total"
+	}
+	return startDate + "-" + endDate
+}
+
+// gets confirmed and recovered cases in a country, for the whole pandemic if startDate is empty
+func getCases(countryName string, startDate string, endDate string, resolution string) (CasesPerCountry, error) {
+	var response CasesPerCountry
+
+	// gets data of confirmed
+	confirmedData, err := getConfirmedData(countryName)
+	if err != nil {
+		return response, err
+	}
+
+	// gets data of recovered
+	recoveredData, err := getRecoveredData(countryName, nil)
+	if err != nil {
+		return response, err
+	}
+
+	confirmedDates := getDates(confirmedData)
+	latestDate := getLatestDate(confirmedDates)
+	usedStart, usedEnd, err := resolveScope(confirmedDates, startDate, endDate, resolution)
+	if err != nil {
+		return response, err
+	}
+	confirmed, err := getScopedValue(confirmedDates, usedStart, usedEnd, latestDate)
+	if err != nil {
+		return response, err
+	}
+	recovered, err := getScopedValue(getDates(recoveredData), usedStart, usedEnd, latestDate)
+	if err != nil {
+		return response, err
+	}
+
+	population, _ := confirmedData.All["population"].(float64)
+	response.Continent, _ = confirmedData.All["continent"].(string)
+	response.Confirmed = int(confirmed)
+	response.Country = countryName
+	response.Recovered = int(recovered)
+	response.Scope = getScopeName(startDate, endDate)
+	response.Start_date = usedStart
+	response.End_date = usedEnd
+	if usedEnd == "" {
+		response.End_date = latestDate
+	}
+	response.Population_percentage = getPopulationPercentage(confirmed, population)
+	response.Metadata = newMetadata(response.End_date, confirmedData.source, recoveredData.source)
+	return response, nil
+}
@@ -0,0 +1,47 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// the cases in a scope are the same as the sum of new cases in the time series of the scope
+func TestCasesMatchTimeSeries(t *testing.T) {
+	useTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/history" && r.URL.Query().Get("country") == "Norway" {
+			w.Write(getTestHistory("Norway", 5000000, 10))
+			return
+		}
+		http.NotFound(w, r)
+	})
+	router := NewApiRouter()
+
+	for _, scope := range []string{"2021-W02", "2021-02", "2021-Q1", "2021-01-01/2021-01-01", "2021-01-05-2021-01-20"} {
+		var cases CasesPerCountry
+		response := serveTest(router, http.MethodGet, "/corona/v2/country/norway?scope="+scope)
+		if err := json.Unmarshal(response.Body.Bytes(), &cases); err != nil || response.Code != http.StatusOK {
+			t.Fatalf("%s: got %d %s", scope, response.Code, response.Body.String())
+		}
+		var series CasesTimeSeries
+		response = serveTest(router, http.MethodGet, "/corona/v2/country/norway/timeseries?granularity=day&scope="+scope)
+		if err := json.Unmarshal(response.Body.Bytes(), &series); err != nil || response.Code != http.StatusOK {
+			t.Fatalf("%s timeseries: got %d %s", scope, response.Code, response.Body.String())
+		}
+		sum := 0
+		for _, point := range series.Series {
+			sum += point.New_cases
+		}
+		if cases.Confirmed != sum || sum != 10*len(series.Series) {
+			t.Errorf("%s: got %d confirmed and %d new cases in %d days in the time series, want the same", scope, cases.Confirmed, sum, len(series.Series))
+		}
+	}
+
+	var cases CasesPerCountry
+	json.Unmarshal(serveTest(router, http.MethodGet, "/corona/v2/country/norway?scope=2021-W02").Body.Bytes(), &cases)
+	var series CasesTimeSeries
+	json.Unmarshal(serveTest(router, http.MethodGet, "/corona/v2/country/norway/timeseries?granularity=week&scope=2021-W02").Body.Bytes(), &series)
+	if len(series.Series) != 1 || series.Series[0].New_cases != cases.Confirmed || cases.Confirmed != 70 {
+		t.Errorf("2021-W02: got %d confirmed and weekly series %+v, want 70 in both", cases.Confirmed, series.Series)
+	}
+}
@@ -0,0 +1,291 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type chartPoint struct {
+	Label string
+	Value float64
+}
+
+// size of charts and sparklines when not given in url
+const (
+	defaultChartWidth      = 640
+	defaultChartHeight     = 320
+	defaultSparklineWidth  = 120
+	defaultSparklineHeight = 24
+)
+
+// smallest size in url, a line chart needs room for its margins (72px across and down) and a plot
+const (
+	minChartWidth    = 120
+	minChartHeight   = 100
+	minSparklineSize = 10
+	maxChartSize     = 4000
+)
+
+// http://localhost:8080/corona/v1/country/{:country_name}/chart.svg{?scope=begin_date-end_date&metric=new_cases|confirmed|recovered|stringency&type=line|sparkline&granularity=day|week|month&width=px&height=px}
+func HandleChart(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName, startDate, endDate, err := getUrlData("country_name", r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		granularity, err := getGranularity(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resolution, err := getDateResolution(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		metric := r.URL.Query().Get("metric")
+		if metric == "" {
+			metric = "new_cases"
+		}
+		chartType := r.URL.Query().Get("type")
+		if chartType == "" {
+			chartType = "line"
+		} else if chartType != "line" && chartType != "sparkline" {
+			writeError(w, r, errors.New("Invalid type '"+chartType+"', should be one of: line, sparkline"))
+			return
+		}
+		width, height, err := getChartSize(r, chartType)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		points, metadata, err := getChartPoints(countryName, metric, startDate, endDate, resolution, granularity)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		http.Header.Add(w.Header(), "content-type", "image/svg+xml")
+		if chartType == "sparkline" {
+			w.Write([]byte(renderSparkline(points, width, height, metadata)))
+		} else {
+			title := countryName + " " + strings.Replace(metric, "_", " ", -1) + " (" + getScopeName(startDate, endDate) + ")"
+			w.Write([]byte(renderLineChart(title, points, width, height, metadata)))
+		}
+		return
+	default:
+		return
+	}
+}
+
+// gets ?width and ?height, with defaults based on the chart type
+func getChartSize(r *http.Request, chartType string) (int, int, error) {
+	width, height := defaultChartWidth, defaultChartHeight
+	minWidth, minHeight := minChartWidth, minChartHeight
+	if chartType == "sparkline" {
+		width, height = defaultSparklineWidth, defaultSparklineHeight
+		minWidth, minHeight = minSparklineSize, minSparklineSize
+	}
+	if widthQuery := r.URL.Query().Get("width"); widthQuery != "" {
+		var err error
+		width, err = strconv.Atoi(widthQuery)
+		if err != nil || width < minWidth || width > maxChartSize {
+			return 0, 0, fmt.Errorf("Invalid width, should be a number between %d and %d", minWidth, maxChartSize)
+		}
+	}
+	if heightQuery := r.URL.Query().Get("height"); heightQuery != "" {
+		var err error
+		height, err = strconv.Atoi(heightQuery)
+		if err != nil || height < minHeight || height > maxChartSize {
+			return 0, 0, fmt.Errorf("Invalid height, should be a number between %d and %d", minHeight, maxChartSize)
+		}
+	}
+	return width, height, nil
+}
+
+// gets the points to draw from the cases or stringency time series, and the metadata of the series
+func getChartPoints(countryName string, metric string, startDate string, endDate string, resolution string, granularity string) ([]chartPoint, *Metadata, error) {
+	var points []chartPoint
+	var metadata *Metadata
+	switch metric {
+	case "stringency":
+		series, err := getStringencyTimeSeries(countryName, startDate, endDate, granularity)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, point := range series.Series {
+			points = append(points, chartPoint{Label: point.Date, Value: point.Stringency})
+		}
+		metadata = series.Metadata
+	case "new_cases", "confirmed", "recovered":
+		series, err := getCasesTimeSeries(countryName, startDate, endDate, resolution, granularity)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, point := range series.Series {
+			value := point.New_cases
+			if metric == "confirmed" {
+				value = point.Confirmed
+			} else if metric == "recovered" {
+				value = point.Recovered
+			}
+			points = append(points, chartPoint{Label: point.Date, Value: float64(value)})
+		}
+		metadata = series.Metadata
+	default:
+		return nil, nil, errors.New("Invalid metric '" + metric + "', should be one of: new_cases, confirmed, recovered, stringency")
+	}
+	if len(points) == 0 {
+		return nil, nil, errors.New("No data to draw for " + countryName)
+	}
+	return points, metadata, nil
+}
+
+// gets the lowest and highest value, the range always includes 0
+func getValueRange(points []chartPoint) (float64, float64) {
+	low, high := 0.0, 0.0
+	for _, point := range points {
+		low = math.Min(low, point.Value)
+		high = math.Max(high, point.Value)
+	}
+	if high == low {
+		high = low + 1
+	}
+	return low, high
+}
+
+// gets a step between axis ticks that is 1, 2 or 5 times a power of ten
+func getTickStep(valueRange float64, ticks int) float64 {
+	rough := valueRange / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(rough)))
+	for _, multiple := range []float64{1, 2, 5, 10} {
+		if multiple*magnitude >= rough {
+			return multiple * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formats an axis value short, like 1.5k or 2M, with only the decimals needed to tell values step apart
+func formatAxisValue(value float64, step float64) string {
+	unit, suffix := 1.0, ""
+	switch abs := math.Abs(value); {
+	case abs >= 1000000:
+		unit, suffix = 1000000, "M"
+	case abs >= 1000:
+		unit, suffix = 1000, "k"
+	}
+	decimals := 0
+	if scaledStep := step / unit; scaledStep > 0 && scaledStep < 1 {
+		decimals = int(math.Ceil(-math.Log10(scaledStep) - 1e-9))
+	}
+	formatted := strconv.FormatFloat(value/unit, 'f', decimals, 64)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	if formatted == "-0" {
+		formatted = "0"
+	}
+	return formatted + suffix
+}
+
+// gets the svg polyline points for the values inside the box
+func getPolylinePoints(points []chartPoint, left float64, top float64, width float64, height float64, low float64, high float64) string {
+	var coordinates []string
+	for i, point := range points {
+		x := left
+		if len(points) > 1 {
+			x = left + width*float64(i)/float64(len(points)-1)
+		}
+		y := top + height - (point.Value-low)/(high-low)*height
+		coordinates = append(coordinates, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return strings.Join(coordinates, " ")
+}
+
+// draws a line chart with title, axes and labels
+func renderLineChart(title string, points []chartPoint, width int, height int, metadata *Metadata) string {
+	const marginLeft, marginRight, marginTop, marginBottom = 56.0, 16.0, 32.0, 40.0
+	plotWidth := float64(width) - marginLeft - marginRight
+	plotHeight := float64(height) - marginTop - marginBottom
+	low, high := getValueRange(points)
+	step := getTickStep(high-low, 5)
+	low = math.Floor(low/step) * step
+	high = math.Ceil(high/step) * step
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`, width, height, width, height)
+	fmt.Fprintf(&svg, `<title>%s</title>`, html.EscapeString(title))
+	svg.WriteString(renderSvgMetadata(metadata))
+	fmt.Fprintf(&svg, `<rect width="100%%" height="100%%" fill="#fff"/>`)
+	fmt.Fprintf(&svg, `<text x="%.1f" y="20" font-size="13" font-weight="bold">%s</text>`, marginLeft, html.EscapeString(title))
+
+	// y axis with grid lines
+	for tick := 0; low+float64(tick)*step <= high+step/2; tick++ {
+		value := low + float64(tick)*step
+		y := marginTop + plotHeight - (value-low)/(high-low)*plotHeight
+		fmt.Fprintf(&svg, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e5e5"/>`, marginLeft, y, marginLeft+plotWidth, y)
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`, marginLeft-6, y, formatAxisValue(value, step))
+	}
+	fmt.Fprintf(&svg, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`, marginLeft, marginTop, marginLeft, marginTop+plotHeight)
+	fmt.Fprintf(&svg, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`, marginLeft, marginTop+plotHeight, marginLeft+plotWidth, marginTop+plotHeight)
+
+	// x axis labels on the first, middle and last point
+	labelIndexes := []int{0, len(points) / 2, len(points) - 1}
+	anchors := []string{"start", "middle", "end"}
+	for i, index := range labelIndexes {
+		if len(points) < 3 && i == 1 {
+			continue
+		}
+		x := marginLeft
+		if len(points) > 1 {
+			x = marginLeft + plotWidth*float64(index)/float64(len(points)-1)
+		}
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="%s">%s</text>`, x, marginTop+plotHeight+18, anchors[i], html.EscapeString(points[index].Label))
+	}
+
+	fmt.Fprintf(&svg, `<polyline fill="none" stroke="#c0392b" stroke-width="1.5" points="%s"/>`, getPolylinePoints(points, marginLeft, marginTop, plotWidth, plotHeight, low, high))
+	svg.WriteString(`</svg>`)
+	return svg.String()
+}
+
+// draws only the line, with a dot on the last value
+func renderSparkline(points []chartPoint, width int, height int, metadata *Metadata) string {
+	const padding = 2.0
+	low, high := getValueRange(points)
+	plotWidth := float64(width) - 2*padding
+	plotHeight := float64(height) - 2*padding
+	last := points[len(points)-1]
+	lastY := padding + plotHeight - (last.Value-low)/(high-low)*plotHeight
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&svg, `<title>%s: %s</title>`, html.EscapeString(last.Label), formatAxisValue(last.Value, 0.01))
+	svg.WriteString(renderSvgMetadata(metadata))
+	fmt.Fprintf(&svg, `<polyline fill="none" stroke="#c0392b" stroke-width="1" points="%s"/>`, getPolylinePoints(points, padding, padding, plotWidth, plotHeight, low, high))
+	fmt.Fprintf(&svg, `<circle cx="%.1f" cy="%.1f" r="1.5" fill="#c0392b"/>`, padding+plotWidth, lastY)
+	svg.WriteString(`</svg>`)
+	return svg.String()
+}
+
+// writes metadata as json in the metadata element of an svg, empty without metadata
+func renderSvgMetadata(metadata *Metadata) string {
+	if metadata == nil {
+		return ""
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return ""
+	}
+	return `<metadata>` + html.EscapeString(string(data)) + `</metadata>`
+}
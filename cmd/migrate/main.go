@@ -0,0 +1,67 @@
+package main
+
+import (
+	"CoronaAPI"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+// migrates the webhook documents in firestore, or in a json file with -input
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only show the changes as a diff, without writing them")
+	to := flag.Int("to", 0, "version to migrate to, the latest if not set")
+	input := flag.String("input", "", "json file with webhook documents by id, migrated in memory instead of firestore")
+	flag.Parse()
+
+	var documents CoronaAPI.WebhookDocuments
+	var err error
+	if *input != "" {
+		documents, err = readDocuments(*input)
+	} else {
+		documents, err = CoronaAPI.OpenFirestoreDocuments()
+	}
+	if err != nil {
+		log.Fatalln("Can't open the webhook documents:", err)
+	}
+
+	err = CoronaAPI.MigrateWebhooks(documents, *to, *dryRun, os.Stdout)
+	if err != nil {
+		log.Fatalln("Migration failed:", err)
+	}
+
+	if *input != "" && !*dryRun { // prints the migrated documents, as there is nowhere to write them
+		migrated, _ := documents.Documents()
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(migrated)
+	}
+}
+
+// reads documents from a json file, whole numbers are read as integers like firestore does
+func readDocuments(path string) (*CoronaAPI.MemoryDocuments, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	var data map[string]map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	for _, document := range data {
+		for key, value := range document {
+			if number, ok := value.(json.Number); ok {
+				if integer, err := number.Int64(); err == nil {
+					document[key] = integer
+				} else {
+					document[key], _ = number.Float64()
+				}
+			}
+		}
+	}
+	return CoronaAPI.NewMemoryDocuments(data), nil
+}
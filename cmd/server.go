@@ -9,22 +9,23 @@ import (
 )
 
 func main() {
-	CoronaAPI.InitFirebase()
+	CoronaAPI.InitWebhookStore()
 	CoronaAPI.ServerStart()
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
 
-	http.HandleFunc("/", CoronaAPI.HandleRoot)
-	http.HandleFunc("/corona/v1/country/", CoronaAPI.HandleCases)
-	http.HandleFunc("/corona/v1/policy/", CoronaAPI.HandleStringencyTrends)
-	http.HandleFunc("/corona/v1/notifications/", CoronaAPI.HandleNotification)
-	http.HandleFunc("/corona/v1/diag/", CoronaAPI.HandleDiag)
+	router := CoronaAPI.NewApiRouter()
 	go CoronaAPI.WebhookRoutine() // webhook check that runs every hour
+	go CoronaAPI.ServeGrpc(":" + grpcPort)
 
-	fmt.Println("Listening on port " + port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	fmt.Println("Listening on port " + port + ", grpc on port " + grpcPort)
+	log.Fatal(http.ListenAndServe(":"+port, router))
 
 }
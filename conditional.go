@@ -0,0 +1,154 @@
+package CoronaAPI
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// responses that know the date of the newest data they are made from
+type dataDateProvider interface {
+	dataDate() string // YYYY-MM-DD, empty if unknown
+}
+
+// writes body with an etag, and answers 304 instead if the client already has it
+// responses with a data date also get last-modified and cache-control
+// the etag is made from the response, so the extern apis are still asked (or the upstream cache used) before a 304,
+// it saves sending the body again but not the requests upstream, those are only limited by the upstream cache
+func writeConditional(w http.ResponseWriter, r *http.Request, format string, body []byte, response interface{}) {
+	etag := getEtag(format, body, response)
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Accept") // the body depends on the format
+
+	var lastModified time.Time
+	if provider, ok := response.(dataDateProvider); ok {
+		if date, err := time.Parse(dateLayout, provider.dataDate()); err == nil {
+			lastModified = date
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+			w.Header().Set("cache-control", "public, max-age="+strconv.Itoa(int(upstreamCacheDuration.Seconds())))
+		}
+	}
+
+	if isNotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(body)
+}
+
+// gets the etag of a response in a format, made from the response without when its sources were fetched and
+// if they were cached, so the etag only changes with the data, body is used if the response can't be made json
+func getEtag(format string, body []byte, response interface{}) string {
+	data, err := json.Marshal(response)
+	if err == nil {
+		var value interface{}
+		if json.Unmarshal(data, &value) == nil {
+			removeFetchDetails(value)
+			data, err = json.Marshal(value)
+		}
+	}
+	if err != nil {
+		data = body
+	}
+	sum := sha256.Sum256(append([]byte(format+"\n"), data...))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// removes Fetched and Cached from every source in the metadata of a decoded json response
+func removeFetchDetails(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if sources, ok := value["Sources"].([]interface{}); ok {
+			for _, source := range sources {
+				if source, ok := source.(map[string]interface{}); ok {
+					delete(source, "Fetched")
+					delete(source, "Cached")
+				}
+			}
+		}
+		for _, field := range value {
+			removeFetchDetails(field)
+		}
+	case []interface{}:
+		for _, element := range value {
+			removeFetchDetails(element)
+		}
+	}
+}
+
+// checks the conditional headers of a get request, If-None-Match is used before If-Modified-Since
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/") // weak comparison
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		return err == nil && !lastModified.After(since)
+	}
+	return false
+}
+
+// the newest date in the data, empty without metadata
+func (metadata *Metadata) dataDate() string {
+	if metadata == nil {
+		return ""
+	}
+	return metadata.Data_date
+}
+
+func (cases CasesPerCountry) dataDate() string {
+	return cases.Metadata.dataDate()
+}
+
+func (trends PolicyStringencyTrends) dataDate() string {
+	return trends.Metadata.dataDate()
+}
+
+func (t CasesTimeSeries) dataDate() string {
+	return t.Metadata.dataDate()
+}
+
+func (t StringencyTimeSeries) dataDate() string {
+	return t.Metadata.dataDate()
+}
+
+func (cases ContinentCases) dataDate() string {
+	return cases.Metadata.dataDate()
+}
+
+func (regions RegionsPerCountry) dataDate() string {
+	return regions.Metadata.dataDate()
+}
+
+func (region CasesPerRegion) dataDate() string {
+	return region.Metadata.dataDate()
+}
+
+func (ranking Ranking) dataDate() string {
+	return ranking.Metadata.dataDate()
+}
+
+func (report AnomalyReport) dataDate() string {
+	return report.Metadata.dataDate()
+}
+
+func (forecast Forecast) dataDate() string {
+	return forecast.Metadata.dataDate()
+}
+
+func (impact PolicyImpact) dataDate() string {
+	return impact.Metadata.dataDate()
+}
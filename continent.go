@@ -0,0 +1,121 @@
+package CoronaAPI
+
+import (
+	"net/http"
+)
+
+type ContinentCases struct {
+	Continent             string
+	Scope                 string
+	Confirmed             int
+	Recovered             int
+	Deaths                int
+	Population            int
+	Population_percentage float64
+	Deaths_percentage     float64
+	Countries             []CountryBreakdown
+	Missing               []string
+	Metadata              *Metadata `json:",omitempty"` // for all countries
+}
+
+type CountryBreakdown struct {
+	CasesPerCountry
+	Deaths int
+}
+
+// http://localhost:8080/corona/v1/continent/{:continent_name}{?scope=begin_date-end_date}
+func HandleContinent(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		http.Header.Add(w.Header(), "content-type", "application/json")
+
+		// gets information in url parameter
+		continentName, startDate, endDate, err := getUrlData("continent_name", r)
+		if err != nil { // if error getting url data (invalid url)
+			writeError(w, r, err)
+			return
+		}
+
+		resolution, err := getDateResolution(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response, err := getContinentCases(continentName, startDate, endDate, resolution)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// gets cases for a single country including deaths
+func getCountryBreakdown(countryName string, startDate string, endDate string, resolution string) (CountryBreakdown, error) {
+	var breakdown CountryBreakdown
+	cases, err := getCases(countryName, startDate, endDate, resolution)
+	if err != nil {
+		return breakdown, err
+	}
+	deathsData, err := getDeathsData(countryName)
+	if err != nil {
+		return breakdown, err
+	}
+	// uses the same dates as the cases
+	deaths, err := getScopedValue(getDates(deathsData), cases.Start_date, cases.End_date, cases.End_date)
+	if err != nil {
+		return breakdown, err
+	}
+	breakdown.CasesPerCountry = cases
+	breakdown.Deaths = int(deaths)
+	breakdown.Metadata = mergeMetadata(cases.Metadata, newMetadata(cases.End_date, deathsData.source))
+	return breakdown, nil
+}
+
+// sums the cases of all countries on a continent
+func getContinentCases(continentName string, startDate string, endDate string, resolution string) (ContinentCases, error) {
+	var response ContinentCases
+	countries, err := getCountriesByContinent(continentName)
+	if err != nil {
+		return response, err
+	}
+
+	// fetches every country at the same time, as there are many of them
+	breakdowns := make([]CountryBreakdown, len(countries))
+	fetchErrors := make([]error, len(countries))
+	fetchConcurrently(len(countries), func(i int) {
+		breakdowns[i], fetchErrors[i] = getCountryBreakdown(countries[i].Name, startDate, endDate, resolution)
+	})
+
+	var population float64 = 0
+	var metadata []*Metadata
+	for i, country := range countries {
+		if fetchErrors[i] != nil { // countries without data are listed, not counted
+			response.Missing = append(response.Missing, country.Name)
+			continue
+		}
+		response.Confirmed += breakdowns[i].Confirmed
+		response.Recovered += breakdowns[i].Recovered
+		response.Deaths += breakdowns[i].Deaths
+		population += country.Population
+		metadata = append(metadata, breakdowns[i].Metadata)
+		breakdowns[i].Metadata = nil // listed once for the continent
+		response.Countries = append(response.Countries, breakdowns[i])
+	}
+	if len(response.Countries) == 0 { // if no country has data, the error is most likely the same for all
+		return response, fetchErrors[0]
+	}
+
+	response.Continent = countries[0].Continent
+	response.Scope = getScopeName(startDate, endDate)
+	response.Population = int(population)
+	response.Population_percentage = getPopulationPercentage(float64(response.Confirmed), population)
+	response.Deaths_percentage = getPopulationPercentage(float64(response.Deaths), population)
+	response.Metadata = mergeMetadata(metadata...)
+	return response, nil
+}
@@ -2,13 +2,20 @@ package CoronaAPI
 
 import (
 	"encoding/json"
-	"errors"
-	"io/ioutil"
-	"net/http"
 )
 
 type CovidTracker struct {
-	StringencyData Stringency `json:"stringencyData"`
+	StringencyData Stringency     `json:"stringencyData"`
+	PolicyActions  []PolicyAction `json:"policyActions"`
+	source         Source         // where the data came from
+}
+
+type PolicyAction struct {
+	Policy_type_code    string
+	Policy_type_display string
+	Policyvalue         interface{} // number, or text for some policies
+	Flagged             bool
+	Notes               string
 }
 
 type Stringency struct {
@@ -23,15 +30,43 @@ type Stringency struct {
 // gets the stringenct data on a spesific date
 func getStringencyData(countryCode string, date string) (CovidTracker, error) {
 	url := "https://covidtrackerapi.bsg.ox.ac.uk/api/v2/stringency/actions/" + countryCode + "/" + date
-	resp, err := http.Get(url)
 	var covidTracker CovidTracker
+	body, source, err := getCachedResponse(url)
 	if err != nil {
-		return covidTracker, errors.New("reponse error from covidtracker (error with extern api)")
+		return covidTracker, newUpstreamError("covidtracker", err, "reponse error from covidtracker (error with extern api)")
 	}
-	body, err := ioutil.ReadAll(resp.Body)
+	json.Unmarshal(body, &covidTracker)
+	covidTracker.source = source
+	return covidTracker, nil
+}
+
+// gets stringency on end date and the change since start date
+func getStringencyTrends(countryName string, startDate string, endDate string) (PolicyStringencyTrends, error) {
+	var response PolicyStringencyTrends
+
+	// gets country code
+	countryCode, codeSource, err := getCountryCodeByName(countryName)
 	if err != nil {
-		return covidTracker, errors.New("Error with ioutil.ReadAll (error with extern api)")
+		return response, err
 	}
-	json.Unmarshal([]byte(body), &covidTracker)
-	return covidTracker, nil
+
+	// gets stringency data on from date
+	dataFromDate, err := getStringencyData(countryCode, startDate)
+	if err != nil {
+		return response, err
+	}
+
+	// gets stringency data on end date
+	dataEndDate, err := getStringencyData(countryCode, endDate)
+	if err != nil {
+		return response, err
+	}
+
+	response.Country = countryName
+	response.Scope = startDate + "-" + endDate
+	response.Stringency = dataEndDate.StringencyData.Stringency
+	response.Trend = dataEndDate.StringencyData.Stringency - dataFromDate.StringencyData.Stringency
+	// without data on the end date the data date is unknown, so it's left empty
+	response.Metadata = newMetadata(dataEndDate.StringencyData.Date_value, codeSource, dataFromDate.source, dataEndDate.source)
+	return response, nil
 }
@@ -1,7 +1,6 @@
 package CoronaAPI
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,6 +10,7 @@ type Diag struct {
 	Mmediagroupapi  string
 	Covidtrackerapi string
 	Registered      int
+	Invalid         int // stored webhooks that can't be read, they are not in Registered
 	Version         string
 	Uptime          float64
 }
@@ -30,7 +30,7 @@ func getServerUptime() float64 {
 func checkStatusCodeApi(r *http.Request, url string) string {
 	request, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		fmt.Errorf("Error in creating request:", err.Error())
+		return strconv.Itoa(http.StatusBadRequest)
 	}
 	client := &http.Client{}
 	res, err := client.Do(request)
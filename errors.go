@@ -0,0 +1,75 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// error with what a v2 error response needs, Error() is only the message so v1 responses stay the same
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Source  string `json:"source,omitempty"` // extern api the error comes from
+	Status  int    `json:"status"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// error when the extern api source could not give the data, the status depends on what went wrong
+func newUpstreamError(source string, err error, message string) error {
+	var netErr net.Error
+	var statusErr statusCodeError
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &apiError{Code: "upstream_timeout", Message: message, Source: source, Status: http.StatusGatewayTimeout}
+	} else if netErr != nil || (errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusServiceUnavailable) {
+		return &apiError{Code: "upstream_unavailable", Message: message, Source: source, Status: http.StatusServiceUnavailable}
+	}
+	return &apiError{Code: "upstream_error", Message: message, Source: source, Status: http.StatusBadGateway}
+}
+
+// error when something asked for does not exist
+func newNotFoundError(code string, source string, message string) error {
+	return &apiError{Code: code, Message: message, Source: source, Status: http.StatusNotFound}
+}
+
+// error when the request is well formed but asks for something that can't be answered, like an invalid scope
+func newUnprocessableError(code string, message string) error {
+	return &apiError{Code: code, Message: message, Status: http.StatusUnprocessableEntity}
+}
+
+// gets the api error of err, errors without one are bad requests
+func getApiError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &apiError{Code: "bad_request", Message: err.Error(), Status: http.StatusBadRequest}
+}
+
+// gets the api version a request was made to, v1 or v2
+func getApiVersion(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/corona/v2/") {
+		return "v2"
+	}
+	return "v1"
+}
+
+// writes an error response, json with code and status for v2 and plain text with 400 for v1
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if getApiVersion(r) == "v1" {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// a copy, so urls in the message can point to the version that was called
+	apiErr := *getApiError(err)
+	apiErr.Message = strings.Replace(apiErr.Message, "/corona/v1/", "/corona/v2/", -1)
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(apiErr)
+}
@@ -0,0 +1,117 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// a response with only some of its fields, made by selectFields
+type fieldSelection struct {
+	value    interface{} // struct or slice of structs with only the selected fields
+	original interface{}
+}
+
+func (selection fieldSelection) MarshalJSON() ([]byte, error) {
+	return json.Marshal(selection.value)
+}
+
+func (selection fieldSelection) rows() interface{} {
+	return getRows(selection.value)
+}
+
+func (selection fieldSelection) dataDate() string {
+	if provider, ok := selection.original.(dataDateProvider); ok {
+		return provider.dataDate()
+	}
+	return ""
+}
+
+// gets the response with only the fields in ?fields=a,b, or the response as it is without ?fields
+// response is a struct or a slice of structs, field names are as in json but not case sensitive
+func selectFields(r *http.Request, response interface{}) (interface{}, error) {
+	fieldsQuery := r.URL.Query().Get("fields")
+	if fieldsQuery == "" {
+		return response, nil
+	}
+	value := reflect.ValueOf(response)
+	structType := value.Type()
+	if structType.Kind() == reflect.Slice {
+		structType = structType.Elem()
+	}
+
+	available := getSelectableFields(structType)
+	var names []string
+	for name := range available {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []reflect.StructField
+	var indexes [][]int
+	selected := map[string]bool{}
+	for _, name := range strings.Split(fieldsQuery, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		field, ok := available[name]
+		if !ok {
+			return nil, errors.New("Invalid field '" + name + "' in fields, should be one or more of: " + strings.Join(names, ", "))
+		}
+		if selected[name] {
+			continue
+		}
+		selected[name] = true
+		indexes = append(indexes, field.Index)
+		field.Index = nil
+		field.Anonymous = false
+		fields = append(fields, field)
+	}
+
+	selectedType := reflect.StructOf(fields)
+	project := func(from reflect.Value) reflect.Value {
+		to := reflect.New(selectedType).Elem()
+		for i, index := range indexes {
+			to.Field(i).Set(from.FieldByIndex(index))
+		}
+		return to
+	}
+	if value.Kind() == reflect.Slice {
+		projected := reflect.MakeSlice(reflect.SliceOf(selectedType), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			projected.Index(i).Set(project(value.Index(i)))
+		}
+		return fieldSelection{value: projected.Interface(), original: response}, nil
+	}
+	return fieldSelection{value: project(value).Interface(), original: response}, nil
+}
+
+// gets the fields of a struct that can be selected, with the lowercase json name as key
+// the index of a field in an embedded struct is the full path to it
+func getSelectableFields(structType reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for embeddedName, embedded := range getSelectableFields(field.Type) {
+				if _, ok := fields[embeddedName]; !ok { // fields in the outer struct win, as in encoding/json
+					embedded.Index = append([]int{i}, embedded.Index...)
+					fields[embeddedName] = embedded
+				}
+			}
+			continue
+		}
+		fields[strings.ToLower(name)] = field
+	}
+	return fields
+}
@@ -0,0 +1,324 @@
+package CoronaAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	firestoreapi "cloud.google.com/go/firestore/apiv1"
+	firebase "firebase.google.com/go"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keeps webhooks in the webhooks collection in cloud firestore
+type firestoreStore struct {
+	ctx    context.Context
+	client *firestore.Client
+
+	mutex       sync.Mutex
+	updateTimes map[string]time.Time // when each document was changed, as read by Documents, for Replace
+}
+
+// initialize firebase/firestore, against the emulator in FIRESTORE_EMULATOR_HOST, or with credentials from
+// FIREBASE_CREDENTIALS_FILE, ./serviceAccountKey.json or the application default credentials, in that order
+// the project id is FIRESTORE_PROJECT_ID or GOOGLE_CLOUD_PROJECT, or else the one in the credentials
+func newFirestoreStore() (*firestoreStore, error) {
+	ctx := context.Background()
+	projectID := os.Getenv("FIRESTORE_PROJECT_ID")
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	var opts []option.ClientOption
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") != "" {
+		// the firestore client connects to the emulator by itself, without credentials
+		if projectID == "" {
+			projectID = "demo-corona-api" // the emulator takes any project id
+		}
+	} else if file := getCredentialsFile(); file != "" {
+		opts = append(opts, option.WithCredentialsFile(file))
+	} else {
+		credentials, err := google.FindDefaultCredentials(ctx, firestoreapi.DefaultAuthScopes()...)
+		if err != nil {
+			return nil, errors.New("no firestore credentials found, set FIRESTORE_EMULATOR_HOST, FIREBASE_CREDENTIALS_FILE or GOOGLE_APPLICATION_CREDENTIALS, " +
+				"or use WEBHOOK_STORE=sqlite or WEBHOOK_STORE=memory to run without google cloud")
+		}
+		opts = append(opts, option.WithCredentials(credentials))
+		if projectID == "" {
+			projectID = credentials.ProjectID
+		}
+	}
+
+	var config *firebase.Config
+	if projectID != "" {
+		config = &firebase.Config{ProjectID: projectID}
+	}
+	app, err := firebase.NewApp(ctx, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	client, err := app.Firestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w, set FIRESTORE_PROJECT_ID", err)
+	}
+
+	// reads one webhook, so a wrong setup stops the server now instead of failing the first request
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, err = client.Collection("webhooks").Limit(1).Documents(checkCtx).Next()
+	if err != nil && err != iterator.Done {
+		client.Close()
+		return nil, fmt.Errorf("can't reach firestore in project %s: %w", projectID, err)
+	}
+	return &firestoreStore{ctx: ctx, client: client, updateTimes: map[string]time.Time{}}, nil
+}
+
+// gets the service account file from FIREBASE_CREDENTIALS_FILE, or ./serviceAccountKey.json if it's there
+func getCredentialsFile() string {
+	if file := os.Getenv("FIREBASE_CREDENTIALS_FILE"); file != "" {
+		return file
+	}
+	if _, err := os.Stat("./serviceAccountKey.json"); err == nil {
+		return "./serviceAccountKey.json"
+	}
+	return ""
+}
+
+// the version of webhookDocument, written to every document, the same as the last migration in migrate.go
+// documents from before there was a version have none, and are read as version 1
+const webhookSchemaVersion = 1
+
+// a webhook as it's kept in the webhooks collection, the id is the document id
+type webhookDocument struct {
+	Schema_version int       `firestore:"schema_version"`
+	Url            string    `firestore:"url"`
+	Timeout        float64   `firestore:"timeout"`
+	Time           time.Time `firestore:"time,serverTimestamp"`
+	Field          string    `firestore:"field"`
+	Country        string    `firestore:"country"`
+	Trigger        string    `firestore:"trigger"`
+	Occurrences    float64   `firestore:"occurrences"`
+}
+
+func newWebhookDocument(webhook WebhookRegistration) webhookDocument {
+	return webhookDocument{
+		Schema_version: webhookSchemaVersion,
+		Url:            webhook.Url,
+		Timeout:        webhook.Timeout,
+		Time:           webhook.Time,
+		Field:          webhook.Field,
+		Country:        webhook.Country,
+		Trigger:        webhook.Trigger,
+		Occurrences:    webhook.Occurrences,
+	}
+}
+
+// reads a webhook from the data of a document, unknown fields are skipped and numbers can be integers
+// a document that doesn't fit or isn't a valid webhook is an error instead of a panic or a webhook that can't be notified
+func decodeWebhookDocument(id string, data map[string]interface{}) (WebhookRegistration, error) {
+	webhook := WebhookRegistration{ID: id}
+	if version, ok := data["schema_version"]; ok && !isDocumentNumber(version) {
+		return webhook, newInvalidDocumentError(id, fmt.Sprintf("schema_version is %T, not a number", version))
+	}
+	if version := getSchemaVersion(data); version > webhookSchemaVersion {
+		return webhook, newInvalidDocumentError(id, fmt.Sprintf("schema version %d is newer than %d", version, webhookSchemaVersion))
+	}
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{{"url", &webhook.Url}, {"field", &webhook.Field}, {"country", &webhook.Country}, {"trigger", &webhook.Trigger}} {
+		if value, ok := data[field.name]; ok && value != nil {
+			text, ok := value.(string)
+			if !ok {
+				return WebhookRegistration{ID: id}, newInvalidDocumentError(id, fmt.Sprintf("%s is %T, not a string", field.name, value))
+			}
+			*field.value = text
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value *float64
+	}{{"timeout", &webhook.Timeout}, {"occurrences", &webhook.Occurrences}} {
+		switch value := data[field.name].(type) {
+		case nil:
+		case int64:
+			*field.value = float64(value)
+		case float64:
+			*field.value = value
+		default:
+			return WebhookRegistration{ID: id}, newInvalidDocumentError(id, fmt.Sprintf("%s is %T, not a number", field.name, value))
+		}
+	}
+	if value, ok := data["time"]; ok && value != nil {
+		documentTime, ok := value.(time.Time)
+		if !ok {
+			return WebhookRegistration{ID: id}, newInvalidDocumentError(id, fmt.Sprintf("time is %T, not a timestamp", value))
+		}
+		webhook.Time = documentTime
+	}
+	if err := isValidData(webhook); err != nil {
+		return WebhookRegistration{ID: id}, newInvalidDocumentError(id, err.Error())
+	}
+	if webhook.Time.IsZero() {
+		return WebhookRegistration{ID: id}, newInvalidDocumentError(id, "Missing time")
+	}
+	return webhook, nil
+}
+
+// checks if a value in a document is a number, firestore gives integers as int64
+func isDocumentNumber(value interface{}) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// error for a stored webhook that can't be read
+func newInvalidDocumentError(id string, reason string) error {
+	return &apiError{Code: "invalid_webhook_document", Message: "Can't read webhook with id " + id + ": " + reason, Status: http.StatusInternalServerError}
+}
+
+// returned by List with the webhooks that can be read, when some documents can't be
+type invalidDocumentsError struct {
+	errs []error // a newInvalidDocumentError for every document
+}
+
+func (e *invalidDocumentsError) Error() string {
+	var messages []string
+	for _, err := range e.errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (f *firestoreStore) Get(id string) (WebhookRegistration, error) {
+	data, err := f.client.Collection("webhooks").Doc(id).Get(f.ctx)
+	var webhook WebhookRegistration
+	if status.Code(err) == codes.NotFound {
+		return webhook, newNotFoundError("unknown_webhook", "", "Can't find webhook with id "+id)
+	} else if err != nil {
+		return webhook, err
+	}
+	return decodeWebhookDocument(data.Ref.ID, data.Data())
+}
+
+func (f *firestoreStore) List() ([]WebhookRegistration, error) {
+	iter := f.client.Collection("webhooks").Documents(f.ctx)
+	var allWebooks []WebhookRegistration
+	var invalid []error
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return allWebooks, err
+		}
+		webhook, err := decodeWebhookDocument(doc.Ref.ID, doc.Data())
+		if err != nil { // one bad document should not hide the other webhooks
+			invalid = append(invalid, err)
+			continue
+		}
+		allWebooks = append(allWebooks, webhook)
+	}
+	if len(invalid) > 0 {
+		return allWebooks, &invalidDocumentsError{errs: invalid}
+	}
+	return allWebooks, nil
+}
+
+func (f *firestoreStore) Add(webhook WebhookRegistration) (string, error) {
+	// adding the webhook to the webhooks collection in cloud firestore
+	document := newWebhookDocument(webhook)
+	document.Time = time.Time{} // set to the server timestamp
+	docRef, _, err := f.client.Collection("webhooks").Add(f.ctx, document)
+	if err != nil {
+		return "", err
+	}
+	return docRef.ID, nil
+}
+
+// updates the fields of an existing document, a set with merge would make a new document from a deleted webhook
+func (f *firestoreStore) Update(id string, newTime time.Time, occurrences float64) error {
+	_, err := f.client.Collection("webhooks").Doc(id).Update(f.ctx, []firestore.Update{
+		{Path: "time", Value: newTime},
+		{Path: "occurrences", Value: occurrences},
+	})
+	if status.Code(err) == codes.NotFound {
+		return newNotFoundError("unknown_webhook", "", "Can't find webhook with id "+id)
+	}
+	return err
+}
+
+func (f *firestoreStore) Delete(id string) error {
+	_, err := f.client.Collection("webhooks").Doc(id).Delete(f.ctx)
+	return err
+}
+
+func (f *firestoreStore) Documents() (map[string]map[string]interface{}, error) {
+	docs, err := f.client.Collection("webhooks").Documents(f.ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]map[string]interface{}{}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, doc := range docs {
+		all[doc.Ref.ID] = doc.Data()
+		f.updateTimes[doc.Ref.ID] = doc.UpdateTime
+	}
+	return all, nil
+}
+
+// replaces a document in a transaction, only if it has not changed since Documents read it,
+// so a webhook the server updates or deletes during a migration is not overwritten with old data
+func (f *firestoreStore) Replace(id string, data map[string]interface{}) error {
+	f.mutex.Lock()
+	readTime, ok := f.updateTimes[id]
+	f.mutex.Unlock()
+	if !ok {
+		return errors.New("not read before it was replaced")
+	}
+	ref := f.client.Collection("webhooks").Doc(id)
+	return f.client.RunTransaction(f.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return errors.New("deleted since it was read, run the migration again")
+		} else if err != nil {
+			return err
+		}
+		if !doc.UpdateTime.Equal(readTime) {
+			return errors.New("changed since it was read, run the migration again")
+		}
+		return tx.Set(ref, data)
+	})
+}
+
+// the applied migration version is kept in migrations/webhooks, outside the webhooks collection
+func (f *firestoreStore) AppliedVersion() (int, error) {
+	doc, err := f.client.Collection("migrations").Doc("webhooks").Get(f.ctx)
+	if status.Code(err) == codes.NotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return getSchemaVersion(map[string]interface{}{"schema_version": doc.Data()["version"]}), nil
+}
+
+func (f *firestoreStore) SetAppliedVersion(version int) error {
+	_, err := f.client.Collection("migrations").Doc("webhooks").Set(f.ctx, map[string]interface{}{
+		"version": version,
+		"applied": firestore.ServerTimestamp,
+	})
+	return err
+}
@@ -0,0 +1,67 @@
+package CoronaAPI
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeWebhookDocument(t *testing.T) {
+	added := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"url": "https://example.com/hook", "timeout": 60.0, "time": added, "field": "confirmed",
+			"country": "Norway", "trigger": "ON_CHANGE", "occurrences": 12.0, "schema_version": int64(webhookSchemaVersion)}
+	}
+	with := func(name string, value interface{}) map[string]interface{} {
+		data := valid()
+		data[name] = value
+		return data
+	}
+	without := func(name string) map[string]interface{} {
+		data := valid()
+		delete(data, name)
+		return data
+	}
+	want := WebhookRegistration{ID: "a", Url: "https://example.com/hook", Timeout: 60, Time: added, Field: "confirmed", Country: "Norway", Trigger: "ON_CHANGE", Occurrences: 12}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		err  string // empty when the document is read as want
+	}{
+		{"valid", valid(), ""},
+		{"legacy int timeout", with("timeout", int64(60)), ""},
+		{"legacy int occurrences", with("occurrences", int64(12)), ""},
+		{"without schema version", without("schema_version"), ""},
+		{"unknown field", with("color", "red"), ""},
+		{"newer schema version", with("schema_version", int64(webhookSchemaVersion+1)), "is newer than"},
+		{"schema version as text", with("schema_version", "2"), "schema_version is string"},
+		{"url as number", with("url", int64(1)), "url is int64"},
+		{"timeout as text", with("timeout", "60"), "timeout is string"},
+		{"time as text", with("time", "2021-03-01"), "time is string"},
+		{"country as map", with("country", map[string]interface{}{"name": "Norway"}), "country is map"},
+		{"missing url", without("url"), "invalid url"},
+		{"invalid url", with("url", "::"), "invalid url"},
+		{"missing timeout", without("timeout"), "invalid timeout"},
+		{"missing field", without("field"), "invalid field"},
+		{"missing country", without("country"), "invalid country"},
+		{"unknown trigger", with("trigger", "ON_SUNDAY"), "invalid trigger"},
+		{"missing time", without("time"), "Missing time"},
+		{"empty document", map[string]interface{}{}, "invalid url"},
+	}
+	for _, test := range tests {
+		webhook, err := decodeWebhookDocument("a", test.data)
+		if test.err == "" {
+			if err != nil || webhook != want {
+				t.Errorf("%s: got %+v %v, want %+v", test.name, webhook, err, want)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) || getApiError(err).Code != "invalid_webhook_document" {
+			t.Errorf("%s: got %v, want an invalid_webhook_document error with %q", test.name, err, test.err)
+		}
+		if webhook != (WebhookRegistration{ID: "a"}) {
+			t.Errorf("%s: got %+v, want only the id", test.name, webhook)
+		}
+	}
+}
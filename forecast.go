@@ -0,0 +1,187 @@
+package CoronaAPI
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Forecast struct {
+	Country      string
+	Model        string
+	Window       int
+	Daily_growth float64 // daily growth rate of new cases in percent
+	Forecast     []ForecastPoint
+	Backtest     ForecastBacktest
+	Metadata     *Metadata `json:",omitempty"`
+}
+
+type ForecastPoint struct {
+	Date      string
+	New_cases float64
+	Lower     float64 // 95% prediction interval
+	Upper     float64
+}
+
+type ForecastBacktest struct {
+	Start_date string
+	End_date   string
+	MAE        float64 // mean absolute error
+	MAPE       float64 // mean absolute percentage error
+}
+
+type logLinearModel struct {
+	intercept  float64
+	slope      float64
+	stdError   float64 // residual standard error in log space
+	n          int
+	meanX      float64
+	sumSquares float64 // sum of (x - meanX)^2
+}
+
+const (
+	forecastWindow      = 28 // days of history the model is fitted on
+	defaultForecastDays = 14
+	maxForecastDays     = 60
+	zScore95            = 1.96
+)
+
+// http://localhost:8080/corona/v1/country/{:country_name}/forecast{?days=number}
+func HandleForecast(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName := strings.Title(strings.ToLower(pathParam(r, "country_name")))
+		days := defaultForecastDays
+		if daysQuery := r.URL.Query().Get("days"); daysQuery != "" {
+			var err error
+			days, err = strconv.Atoi(daysQuery)
+			if err != nil || days < 1 || days > maxForecastDays {
+				writeError(w, r, errors.New("Invalid days, should be a number between 1 and "+strconv.Itoa(maxForecastDays)))
+				return
+			}
+		}
+
+		response, err := getForecast(countryName, days)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// forecasts daily new cases with log-linear regression on the last days
+func getForecast(countryName string, days int) (Forecast, error) {
+	var response Forecast
+	series, err := getCasesTimeSeries(countryName, "", "", resolveExact, "day")
+	if err != nil {
+		return response, err
+	}
+	daily := series.Series
+	if len(daily) < forecastWindow+days {
+		return response, errors.New("Not enough history to forecast " + strconv.Itoa(days) + " days for " + countryName)
+	}
+
+	model := fitLogLinear(daily[len(daily)-forecastWindow:])
+	lastDate, err := time.Parse(dateLayout, daily[len(daily)-1].Date)
+	if err != nil {
+		return response, newUpstreamError("mmediagroup", nil, "Can't read the dates in data from mmediagroup (error with extern api)")
+	}
+	for day := 1; day <= days; day++ {
+		prediction, lower, upper := model.predict(float64(forecastWindow - 1 + day))
+		response.Forecast = append(response.Forecast, ForecastPoint{
+			Date:      lastDate.AddDate(0, 0, day).Format(dateLayout),
+			New_cases: roundTwoDecimals(prediction),
+			Lower:     roundTwoDecimals(lower),
+			Upper:     roundTwoDecimals(upper),
+		})
+	}
+
+	response.Country = countryName
+	response.Model = "log-linear regression"
+	response.Window = forecastWindow
+	response.Daily_growth = roundTwoDecimals((math.Exp(model.slope) - 1) * 100)
+	response.Backtest = backtestLogLinear(daily, days)
+	response.Metadata = series.Metadata
+	return response, nil
+}
+
+// fits ln(new cases + 1) = intercept + slope * day, negative days count as 0
+func fitLogLinear(daily []CasesPoint) logLinearModel {
+	var model logLinearModel
+	model.n = len(daily)
+	var sumY float64
+	for i, point := range daily {
+		model.meanX += float64(i)
+		sumY += math.Log(math.Max(float64(point.New_cases), 0) + 1)
+	}
+	model.meanX /= float64(model.n)
+	meanY := sumY / float64(model.n)
+
+	var sumXY float64
+	for i, point := range daily {
+		x := float64(i) - model.meanX
+		y := math.Log(math.Max(float64(point.New_cases), 0)+1) - meanY
+		sumXY += x * y
+		model.sumSquares += x * x
+	}
+	model.slope = sumXY / model.sumSquares
+	model.intercept = meanY - model.slope*model.meanX
+
+	var residuals float64
+	for i, point := range daily {
+		residual := math.Log(math.Max(float64(point.New_cases), 0)+1) - (model.intercept + model.slope*float64(i))
+		residuals += residual * residual
+	}
+	if model.n > 2 {
+		model.stdError = math.Sqrt(residuals / float64(model.n-2))
+	}
+	return model
+}
+
+// predicts new cases on day x with a 95% prediction interval
+func (model logLinearModel) predict(x float64) (float64, float64, float64) {
+	logPrediction := model.intercept + model.slope*x
+	margin := zScore95 * model.stdError * math.Sqrt(1+1/float64(model.n)+(x-model.meanX)*(x-model.meanX)/model.sumSquares)
+	prediction := math.Max(math.Exp(logPrediction)-1, 0)
+	lower := math.Max(math.Exp(logPrediction-margin)-1, 0)
+	upper := math.Exp(logPrediction+margin) - 1
+	return prediction, lower, upper
+}
+
+// fits the model on the window before the last days and compares the forecast with what happened
+func backtestLogLinear(daily []CasesPoint, days int) ForecastBacktest {
+	var backtest ForecastBacktest
+	end := len(daily) - days
+	model := fitLogLinear(daily[end-forecastWindow : end])
+	var absoluteErrors, percentageErrors float64
+	percentageCount := 0
+	for day := 0; day < days; day++ {
+		prediction, _, _ := model.predict(float64(forecastWindow + day))
+		actual := float64(daily[end+day].New_cases)
+		absoluteErrors += math.Abs(prediction - actual)
+		if actual > 0 { // days without cases can't have a percentage error
+			percentageErrors += math.Abs(prediction-actual) / actual * 100
+			percentageCount++
+		}
+	}
+	backtest.Start_date = daily[end].Date
+	backtest.End_date = daily[len(daily)-1].Date
+	backtest.MAE = roundTwoDecimals(absoluteErrors / float64(days))
+	if percentageCount > 0 {
+		backtest.MAPE = roundTwoDecimals(percentageErrors / float64(percentageCount))
+	}
+	return backtest
+}
+
+// rounds to two decimals
+func roundTwoDecimals(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -6,6 +6,11 @@ require (
 	cloud.google.com/go/firestore v1.5.0
 	cloud.google.com/go/storage v1.14.0 // indirect
 	firebase.google.com/go v3.13.0+incompatible
-	golang.org/x/oauth2 v0.0.0-20210323180902-22b0adad7558 // indirect
+	github.com/golang/protobuf v1.4.3
+	github.com/graph-gophers/graphql-go v1.3.0
+	github.com/mattn/go-sqlite3 v1.14.6
+	golang.org/x/oauth2 v0.0.0-20210323180902-22b0adad7558
 	google.golang.org/api v0.43.0
+	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
 )
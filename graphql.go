@@ -0,0 +1,409 @@
+package CoronaAPI
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	country(name: String!): Country
+	countries(continent: String): [Country!]!
+	webhooks: [Webhook!]!
+	invalidWebhooks: [String!]!
+	webhook(id: ID!): Webhook
+}
+
+type Country {
+	name: String!
+	continent: String!
+	abbreviation: String!
+	population: Float!
+	cases(scope: String, resolve: String): Cases!
+	timeseries(scope: String, granularity: String, resolve: String): [CasesPoint!]!
+	stringency(scope: String, granularity: String): [StringencyPoint!]!
+	policyTrend(scope: String!): PolicyTrend!
+	policyActions(date: String!): [PolicyAction!]!
+}
+
+type Cases {
+	scope: String!
+	confirmed: Int!
+	recovered: Int!
+	populationPercentage: Float!
+	startDate: String!
+	endDate: String!
+}
+
+type CasesPoint {
+	date: String!
+	newCases: Int!
+	confirmed: Int!
+	recovered: Int!
+}
+
+type StringencyPoint {
+	date: String!
+	stringency: Float!
+}
+
+type PolicyTrend {
+	scope: String!
+	stringency: Float!
+	trend: Float!
+}
+
+type PolicyAction {
+	code: String!
+	name: String!
+	value: String!
+	flagged: Boolean!
+	notes: String!
+}
+
+type Webhook {
+	id: ID!
+	url: String!
+	timeout: Float!
+	time: String!
+	field: String!
+	country: String!
+	trigger: String!
+	occurrences: Float!
+}
+`
+
+var graphqlHandler = &relay.Handler{Schema: graphql.MustParseSchema(graphqlSchema, &graphqlResolver{})}
+
+type loaderKey struct{}
+
+// remembers the results of fetch functions during a single graphql request, so each is only called once
+type requestLoader struct {
+	mutex   sync.Mutex
+	results map[string]*loaderResult
+}
+
+type loaderResult struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// http://localhost:8080/corona/graphql
+func HandleGraphql(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+
+	case http.MethodPost:
+		loader := &requestLoader{results: map[string]*loaderResult{}}
+		graphqlHandler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loaderKey{}, loader)))
+		return
+	default:
+		return
+	}
+}
+
+// gets the value for key from the request loader, calls fetch if it's not fetched yet
+func load(ctx context.Context, key string, fetch func() (interface{}, error)) (interface{}, error) {
+	loader, ok := ctx.Value(loaderKey{}).(*requestLoader)
+	if !ok { // outside a graphql request
+		return fetch()
+	}
+	loader.mutex.Lock()
+	result, ok := loader.results[key]
+	if !ok {
+		result = &loaderResult{}
+		loader.results[key] = result
+	}
+	loader.mutex.Unlock()
+	result.once.Do(func() { result.value, result.err = fetch() })
+	return result.value, result.err
+}
+
+// gets the start and end date of an optional scope argument
+func getGraphqlScope(scope *string) (string, string, error) {
+	if scope == nil || *scope == "" {
+		return "", "", nil
+	}
+	return parseScope(*scope, time.Now())
+}
+
+// gets an optional argument, or fallback if not given
+func getGraphqlArgument(argument *string, fallback string) string {
+	if argument == nil || *argument == "" {
+		return fallback
+	}
+	return *argument
+}
+
+type graphqlResolver struct{}
+
+func (*graphqlResolver) Country(ctx context.Context, args struct{ Name string }) (*countryResolver, error) {
+	registry, err := load(ctx, "registry", func() (interface{}, error) { return getCountryRegistry() })
+	if err != nil {
+		return nil, err
+	}
+	for _, country := range registry.(map[string]Country) {
+		if strings.EqualFold(country.Name, args.Name) {
+			return &countryResolver{country}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (*graphqlResolver) Countries(ctx context.Context, args struct{ Continent *string }) ([]*countryResolver, error) {
+	var countries []Country
+	if args.Continent != nil && *args.Continent != "" {
+		var err error
+		countries, err = getCountriesByContinent(*args.Continent)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		registry, err := load(ctx, "registry", func() (interface{}, error) { return getCountryRegistry() })
+		if err != nil {
+			return nil, err
+		}
+		for _, country := range registry.(map[string]Country) {
+			countries = append(countries, country)
+		}
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].Name < countries[j].Name }) // same order every time
+	var resolvers []*countryResolver
+	for _, country := range countries {
+		resolvers = append(resolvers, &countryResolver{country})
+	}
+	return resolvers, nil
+}
+
+// the webhooks that can be read and the errors of the ones that can't, from a single getWebhooks
+type loadedWebhooks struct {
+	webhooks []WebhookRegistration
+	invalid  []error
+}
+
+func loadWebhooks(ctx context.Context) (loadedWebhooks, error) {
+	loaded, err := load(ctx, "webhooks", func() (interface{}, error) {
+		webhooks, invalid, err := getWebhooks()
+		return loadedWebhooks{webhooks, invalid}, err
+	})
+	if err != nil {
+		return loadedWebhooks{}, err
+	}
+	return loaded.(loadedWebhooks), nil
+}
+
+func (*graphqlResolver) Webhooks(ctx context.Context) ([]*webhookResolver, error) {
+	loaded, err := loadWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*webhookResolver
+	for _, webhook := range loaded.webhooks {
+		resolvers = append(resolvers, &webhookResolver{webhook})
+	}
+	return resolvers, nil
+}
+
+// messages of the stored webhooks that can't be read, they are not in webhooks
+func (*graphqlResolver) InvalidWebhooks(ctx context.Context) ([]string, error) {
+	loaded, err := loadWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages := []string{}
+	for _, err := range loaded.invalid {
+		messages = append(messages, err.Error())
+	}
+	return messages, nil
+}
+
+func (*graphqlResolver) Webhook(ctx context.Context, args struct{ ID graphql.ID }) (*webhookResolver, error) {
+	webhook, err := getSingleWebhook(string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	return &webhookResolver{webhook}, nil
+}
+
+type countryResolver struct {
+	country Country
+}
+
+func (c *countryResolver) Name() string         { return c.country.Name }
+func (c *countryResolver) Continent() string    { return c.country.Continent }
+func (c *countryResolver) Abbreviation() string { return c.country.Abbreviation }
+func (c *countryResolver) Population() float64  { return c.country.Population }
+
+func (c *countryResolver) Cases(ctx context.Context, args struct{ Scope, Resolve *string }) (*casesResolver, error) {
+	startDate, endDate, err := getGraphqlScope(args.Scope)
+	if err != nil {
+		return nil, err
+	}
+	resolution, err := parseDateResolution(getGraphqlArgument(args.Resolve, ""))
+	if err != nil {
+		return nil, err
+	}
+	key := "cases/" + c.country.Name + "/" + startDate + "/" + endDate + "/" + resolution
+	cases, err := load(ctx, key, func() (interface{}, error) { return getCases(c.country.Name, startDate, endDate, resolution) })
+	if err != nil {
+		return nil, err
+	}
+	return &casesResolver{cases.(CasesPerCountry)}, nil
+}
+
+func (c *countryResolver) Timeseries(ctx context.Context, args struct{ Scope, Granularity, Resolve *string }) ([]*casesPointResolver, error) {
+	startDate, endDate, err := getGraphqlScope(args.Scope)
+	if err != nil {
+		return nil, err
+	}
+	granularity, err := parseGranularity(getGraphqlArgument(args.Granularity, ""))
+	if err != nil {
+		return nil, err
+	}
+	resolution, err := parseDateResolution(getGraphqlArgument(args.Resolve, ""))
+	if err != nil {
+		return nil, err
+	}
+	key := "timeseries/" + c.country.Name + "/" + startDate + "/" + endDate + "/" + granularity + "/" + resolution
+	series, err := load(ctx, key, func() (interface{}, error) {
+		return getCasesTimeSeries(c.country.Name, startDate, endDate, resolution, granularity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*casesPointResolver
+	for _, point := range series.(CasesTimeSeries).Series {
+		resolvers = append(resolvers, &casesPointResolver{point})
+	}
+	return resolvers, nil
+}
+
+func (c *countryResolver) Stringency(ctx context.Context, args struct{ Scope, Granularity *string }) ([]*stringencyPointResolver, error) {
+	startDate, endDate, err := getGraphqlScope(args.Scope)
+	if err != nil {
+		return nil, err
+	}
+	granularity, err := parseGranularity(getGraphqlArgument(args.Granularity, ""))
+	if err != nil {
+		return nil, err
+	}
+	key := "stringency/" + c.country.Name + "/" + startDate + "/" + endDate + "/" + granularity
+	series, err := load(ctx, key, func() (interface{}, error) {
+		return getStringencyTimeSeries(c.country.Name, startDate, endDate, granularity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*stringencyPointResolver
+	for _, point := range series.(StringencyTimeSeries).Series {
+		resolvers = append(resolvers, &stringencyPointResolver{point})
+	}
+	return resolvers, nil
+}
+
+func (c *countryResolver) PolicyTrend(ctx context.Context, args struct{ Scope string }) (*policyTrendResolver, error) {
+	startDate, endDate, err := getGraphqlScope(&args.Scope)
+	if err != nil {
+		return nil, err
+	}
+	key := "policy/" + c.country.Name + "/" + startDate + "/" + endDate
+	trends, err := load(ctx, key, func() (interface{}, error) { return getStringencyTrends(c.country.Name, startDate, endDate) })
+	if err != nil {
+		return nil, err
+	}
+	return &policyTrendResolver{trends.(PolicyStringencyTrends)}, nil
+}
+
+func (c *countryResolver) PolicyActions(ctx context.Context, args struct{ Date string }) ([]*policyActionResolver, error) {
+	countryCode, err := load(ctx, "code/"+c.country.Name, func() (interface{}, error) {
+		code, _, err := getCountryCodeByName(c.country.Name)
+		return code, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := getStringencyData(countryCode.(string), args.Date)
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []*policyActionResolver
+	for _, action := range data.PolicyActions {
+		resolvers = append(resolvers, &policyActionResolver{action})
+	}
+	return resolvers, nil
+}
+
+type casesResolver struct {
+	cases CasesPerCountry
+}
+
+func (c *casesResolver) Scope() string                 { return c.cases.Scope }
+func (c *casesResolver) Confirmed() int32              { return int32(c.cases.Confirmed) }
+func (c *casesResolver) Recovered() int32              { return int32(c.cases.Recovered) }
+func (c *casesResolver) PopulationPercentage() float64 { return c.cases.Population_percentage }
+func (c *casesResolver) StartDate() string             { return c.cases.Start_date }
+func (c *casesResolver) EndDate() string               { return c.cases.End_date }
+
+type casesPointResolver struct {
+	point CasesPoint
+}
+
+func (p *casesPointResolver) Date() string     { return p.point.Date }
+func (p *casesPointResolver) NewCases() int32  { return int32(p.point.New_cases) }
+func (p *casesPointResolver) Confirmed() int32 { return int32(p.point.Confirmed) }
+func (p *casesPointResolver) Recovered() int32 { return int32(p.point.Recovered) }
+
+type stringencyPointResolver struct {
+	point StringencyPoint
+}
+
+func (p *stringencyPointResolver) Date() string        { return p.point.Date }
+func (p *stringencyPointResolver) Stringency() float64 { return p.point.Stringency }
+
+type policyTrendResolver struct {
+	trends PolicyStringencyTrends
+}
+
+func (p *policyTrendResolver) Scope() string       { return p.trends.Scope }
+func (p *policyTrendResolver) Stringency() float64 { return p.trends.Stringency }
+func (p *policyTrendResolver) Trend() float64      { return p.trends.Trend }
+
+type policyActionResolver struct {
+	action PolicyAction
+}
+
+func (p *policyActionResolver) Code() string  { return p.action.Policy_type_code }
+func (p *policyActionResolver) Name() string  { return p.action.Policy_type_display }
+func (p *policyActionResolver) Flagged() bool { return p.action.Flagged }
+func (p *policyActionResolver) Notes() string { return p.action.Notes }
+func (p *policyActionResolver) Value() string {
+	if p.action.Policyvalue == nil {
+		return ""
+	}
+	return fmt.Sprint(p.action.Policyvalue)
+}
+
+type webhookResolver struct {
+	webhook WebhookRegistration
+}
+
+func (h *webhookResolver) ID() graphql.ID       { return graphql.ID(h.webhook.ID) }
+func (h *webhookResolver) Url() string          { return h.webhook.Url }
+func (h *webhookResolver) Timeout() float64     { return h.webhook.Timeout }
+func (h *webhookResolver) Time() string         { return h.webhook.Time.Format(time.RFC3339) }
+func (h *webhookResolver) Field() string        { return h.webhook.Field }
+func (h *webhookResolver) Country() string      { return h.webhook.Country }
+func (h *webhookResolver) Trigger() string      { return h.webhook.Trigger }
+func (h *webhookResolver) Occurrences() float64 { return h.webhook.Occurrences }
@@ -0,0 +1,214 @@
+package CoronaAPI
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	coronapb "CoronaAPI/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc -I proto --go_out=proto --go_opt=paths=source_relative --go-grpc_out=proto --go-grpc_opt=paths=source_relative proto/corona.proto
+
+type grpcServer struct {
+	coronapb.UnimplementedCoronaServer
+}
+
+// starts the grpc server on address, runs next to the http server
+func ServeGrpc(address string) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	server := grpc.NewServer()
+	coronapb.RegisterCoronaServer(server, &grpcServer{})
+	log.Fatal(server.Serve(listener))
+}
+
+// converts an error to a grpc status with the same meaning as the v2 http status
+func toGrpcError(err error) error {
+	switch getApiError(err).Status {
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case http.StatusInternalServerError:
+		return status.Error(codes.Internal, err.Error())
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return status.Error(codes.Unavailable, err.Error())
+	case http.StatusGatewayTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// gets the start and end date of a scope, empty scope is no scope
+func getGrpcScope(scope string) (string, string, error) {
+	if scope == "" {
+		return "", "", nil
+	}
+	startDate, endDate, err := parseScope(scope, time.Now())
+	if err != nil {
+		return "", "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return startDate, endDate, nil
+}
+
+func (*grpcServer) GetCases(ctx context.Context, request *coronapb.CasesRequest) (*coronapb.Cases, error) {
+	startDate, endDate, err := getGrpcScope(request.Scope)
+	if err != nil {
+		return nil, err
+	}
+	resolution, err := parseDateResolution(request.Resolve)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cases, err := getCases(strings.Title(strings.ToLower(request.Country)), startDate, endDate, resolution)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	return &coronapb.Cases{
+		Country:              cases.Country,
+		Continent:            cases.Continent,
+		Scope:                cases.Scope,
+		Confirmed:            int64(cases.Confirmed),
+		Recovered:            int64(cases.Recovered),
+		PopulationPercentage: cases.Population_percentage,
+		StartDate:            cases.Start_date,
+		EndDate:              cases.End_date,
+	}, nil
+}
+
+func (*grpcServer) GetStringency(ctx context.Context, request *coronapb.StringencyRequest) (*coronapb.StringencyTrend, error) {
+	startDate, endDate, err := getGrpcScope(request.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if startDate == "" {
+		return nil, status.Error(codes.InvalidArgument, "scope is required")
+	}
+	trends, err := getStringencyTrends(strings.Title(strings.ToLower(request.Country)), startDate, endDate)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	return &coronapb.StringencyTrend{
+		Country:    trends.Country,
+		Scope:      trends.Scope,
+		Stringency: trends.Stringency,
+		Trend:      trends.Trend,
+	}, nil
+}
+
+func (*grpcServer) StreamTimeSeries(request *coronapb.TimeSeriesRequest, stream coronapb.Corona_StreamTimeSeriesServer) error {
+	startDate, endDate, err := getGrpcScope(request.Scope)
+	if err != nil {
+		return err
+	}
+	granularity, err := parseGranularity(request.Granularity)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resolution, err := parseDateResolution(request.Resolve)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	countryName := strings.Title(strings.ToLower(request.Country))
+
+	var points []*coronapb.TimeSeriesPoint
+	if request.Series == coronapb.TimeSeriesRequest_STRINGENCY {
+		series, err := getStringencyTimeSeries(countryName, startDate, endDate, granularity)
+		if err != nil {
+			return toGrpcError(err)
+		}
+		for _, point := range series.Series {
+			points = append(points, &coronapb.TimeSeriesPoint{Date: point.Date, Stringency: point.Stringency})
+		}
+	} else {
+		series, err := getCasesTimeSeries(countryName, startDate, endDate, resolution, granularity)
+		if err != nil {
+			return toGrpcError(err)
+		}
+		for _, point := range series.Series {
+			points = append(points, &coronapb.TimeSeriesPoint{
+				Date:      point.Date,
+				NewCases:  int64(point.New_cases),
+				Confirmed: int64(point.Confirmed),
+				Recovered: int64(point.Recovered),
+			})
+		}
+	}
+	for _, point := range points {
+		if err := stream.Send(point); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// converts a webhook to its grpc message
+func toGrpcWebhook(webhook WebhookRegistration) *coronapb.Webhook {
+	return &coronapb.Webhook{
+		Id:          webhook.ID,
+		Url:         webhook.Url,
+		Timeout:     webhook.Timeout,
+		Time:        webhook.Time.Format(time.RFC3339),
+		Field:       webhook.Field,
+		Country:     webhook.Country,
+		Trigger:     webhook.Trigger,
+		Occurrences: webhook.Occurrences,
+	}
+}
+
+func (*grpcServer) ListWebhooks(ctx context.Context, request *coronapb.ListWebhooksRequest) (*coronapb.ListWebhooksResponse, error) {
+	webhooks, invalid, err := getWebhooks()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var response coronapb.ListWebhooksResponse
+	for _, webhook := range webhooks {
+		response.Webhooks = append(response.Webhooks, toGrpcWebhook(webhook))
+	}
+	for _, err := range invalid {
+		response.InvalidWebhooks = append(response.InvalidWebhooks, err.Error())
+	}
+	return &response, nil
+}
+
+func (*grpcServer) GetWebhook(ctx context.Context, request *coronapb.GetWebhookRequest) (*coronapb.Webhook, error) {
+	webhook, err := getSingleWebhook(request.Id)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	return toGrpcWebhook(webhook), nil
+}
+
+func (*grpcServer) CreateWebhook(ctx context.Context, request *coronapb.CreateWebhookRequest) (*coronapb.Webhook, error) {
+	var webhookRegistration WebhookRegistration
+	webhookRegistration.Url = request.Url
+	webhookRegistration.Timeout = request.Timeout
+	webhookRegistration.Field = request.Field
+	webhookRegistration.Country = request.Country
+	webhookRegistration.Trigger = request.Trigger
+	id, err := addWebhook(webhookRegistration)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	webhook, err := getSingleWebhook(id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toGrpcWebhook(webhook), nil
+}
+
+func (*grpcServer) DeleteWebhook(ctx context.Context, request *coronapb.DeleteWebhookRequest) (*coronapb.DeleteWebhookResponse, error) {
+	err := deleteSingleWebhook(request.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &coronapb.DeleteWebhookResponse{}, nil
+}
@@ -0,0 +1,40 @@
+package CoronaAPI
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	coronapb "CoronaAPI/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToGrpcError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{errors.New("Invalid scope"), codes.InvalidArgument},
+		{newUnprocessableError("no_data", "No data in the scope"), codes.InvalidArgument},
+		{newNotFoundError("unknown_country", "restcountries", "Unknown country"), codes.NotFound},
+		{newInvalidDocumentError("a", "url is missing"), codes.Internal},
+		{newUpstreamError("mmediagroup", errors.New("bad response"), "Extern api failed"), codes.Unavailable},
+		{newUpstreamError("mmediagroup", statusCodeError{503}, "Extern api failed"), codes.Unavailable},
+		{newUpstreamError("mmediagroup", context.DeadlineExceeded, "Extern api timed out"), codes.DeadlineExceeded},
+	}
+	for _, test := range tests {
+		if code := status.Code(toGrpcError(test.err)); code != test.code {
+			t.Errorf("%v: got %v, want %v", test.err, code, test.code)
+		}
+	}
+}
+
+func TestGrpcGetWebhookNotFound(t *testing.T) {
+	store = newMemoryStore()
+	_, err := (&grpcServer{}).GetWebhook(context.Background(), &coronapb.GetWebhookRequest{Id: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("got %v, want NotFound", err)
+	}
+}
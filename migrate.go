@@ -0,0 +1,218 @@
+package CoronaAPI
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// a change to the stored webhook documents, the last version is webhookSchemaVersion
+type migration struct {
+	Version     int
+	Description string
+	Apply       func(data map[string]interface{}) // changes a document from the version before, must be safe to run twice
+}
+
+var migrations = []migration{
+	{
+		Version:     1,
+		Description: "adds schema_version to documents from before versioning, and stores timeout and occurrences as floats",
+		Apply: func(data map[string]interface{}) {
+			for _, name := range []string{"timeout", "occurrences"} {
+				if value, ok := data[name].(int64); ok {
+					data[name] = float64(value)
+				}
+			}
+		},
+	},
+}
+
+// webhook documents as they are stored, for migrations
+type WebhookDocuments interface {
+	Documents() (map[string]map[string]interface{}, error) // all documents by id
+	Replace(id string, data map[string]interface{}) error  // can fail if the document changed after Documents read it
+	AppliedVersion() (int, error)                          // 0 if no migration has been applied
+	SetAppliedVersion(version int) error
+}
+
+// opens the webhook documents in firestore, set up as for the server
+func OpenFirestoreDocuments() (WebhookDocuments, error) {
+	return newFirestoreStore()
+}
+
+// migrates every webhook document to version to, 0 is the latest version
+// with dryRun nothing is written, the changes are only written to out as a diff
+func MigrateWebhooks(documents WebhookDocuments, to int, dryRun bool, out io.Writer) error {
+	latest := migrations[len(migrations)-1].Version
+	if to == 0 {
+		to = latest
+	}
+	if to < 1 || to > latest {
+		return fmt.Errorf("Invalid version %d, should be from 1 to %d", to, latest)
+	}
+	applied, err := documents.AppliedVersion()
+	if err != nil {
+		return err
+	}
+	if applied > to {
+		return fmt.Errorf("Version %d is already applied, migrating back to %d is not supported", applied, to)
+	}
+
+	all, err := documents.Documents()
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for id := range all {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	changed := 0
+	for _, id := range ids {
+		data := all[id]
+		version := getSchemaVersion(data)
+		if version > latest {
+			fmt.Fprintf(out, "skipping webhook %s: schema version %d is newer than %d\n", id, version, latest)
+			continue
+		}
+		migrated := copyDocument(data)
+		for _, m := range migrations {
+			if m.Version > version && m.Version <= to {
+				m.Apply(migrated)
+				migrated["schema_version"] = int64(m.Version)
+			}
+		}
+		diff := getDocumentDiff(data, migrated)
+		if len(diff) == 0 {
+			continue
+		}
+		changed++
+		fmt.Fprintf(out, "webhook %s\n%s", id, strings.Join(diff, ""))
+		if !dryRun {
+			if err := documents.Replace(id, migrated); err != nil {
+				return fmt.Errorf("webhook %s: %w", id, err)
+			}
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "dry run: %d of %d webhooks would change to version %d\n", changed, len(ids), to)
+		return nil
+	}
+	if err := documents.SetAppliedVersion(to); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%d of %d webhooks changed, version %d applied\n", changed, len(ids), to)
+	return nil
+}
+
+// gets the schema version of a document, 0 if it has none
+func getSchemaVersion(data map[string]interface{}) int {
+	switch version := data["schema_version"].(type) {
+	case int64:
+		return int(version)
+	case float64:
+		return int(version)
+	}
+	return 0
+}
+
+func copyDocument(data map[string]interface{}) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for key, value := range data {
+		copied[key] = value
+	}
+	return copied
+}
+
+// gets the lines of a diff from one document to another, sorted by field name
+func getDocumentDiff(from map[string]interface{}, to map[string]interface{}) []string {
+	var names []string
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diff []string
+	for _, name := range names {
+		oldValue, inFrom := from[name]
+		newValue, inTo := to[name]
+		if inFrom && inTo && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if inFrom {
+			diff = append(diff, "  - "+name+": "+formatDocumentValue(oldValue)+"\n")
+		}
+		if inTo {
+			diff = append(diff, "  + "+name+": "+formatDocumentValue(newValue)+"\n")
+		}
+	}
+	return diff
+}
+
+// formats a value with its type, so a change from an integer to a float shows in the diff
+func formatDocumentValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v (%T)", value, value)
+}
+
+// webhook documents in memory, for trying migrations without firestore
+type MemoryDocuments struct {
+	mutex   sync.Mutex
+	data    map[string]map[string]interface{}
+	version int
+}
+
+func NewMemoryDocuments(data map[string]map[string]interface{}) *MemoryDocuments {
+	if data == nil {
+		data = map[string]map[string]interface{}{}
+	}
+	return &MemoryDocuments{data: data}
+}
+
+func (m *MemoryDocuments) Documents() (map[string]map[string]interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	all := map[string]map[string]interface{}{}
+	for id, data := range m.data {
+		all[id] = copyDocument(data)
+	}
+	return all, nil
+}
+
+func (m *MemoryDocuments) Replace(id string, data map[string]interface{}) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.data[id] = copyDocument(data)
+	return nil
+}
+
+func (m *MemoryDocuments) AppliedVersion() (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.version, nil
+}
+
+func (m *MemoryDocuments) SetAppliedVersion(version int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.version = version
+	return nil
+}
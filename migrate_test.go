@@ -0,0 +1,209 @@
+package CoronaAPI
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var latestSchemaVersion = migrations[len(migrations)-1].Version
+
+// documents as they were stored before versioning, with integers from the firestore client
+func newUnversionedDocuments() *MemoryDocuments {
+	return NewMemoryDocuments(map[string]map[string]interface{}{
+		"a": {"url": "https://example.com/a", "timeout": int64(60), "occurrences": int64(12), "field": "confirmed"},
+		"b": {"url": "https://example.com/b", "timeout": 30.0, "occurrences": 1.5, "field": "stringency", "schema_version": int64(1)},
+	})
+}
+
+func TestMigrateWebhooks(t *testing.T) {
+	documents := newUnversionedDocuments()
+	var out bytes.Buffer
+	if err := MigrateWebhooks(documents, 0, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	migrated, _ := documents.Documents()
+	want := map[string]interface{}{"url": "https://example.com/a", "timeout": 60.0, "occurrences": 12.0, "field": "confirmed", "schema_version": int64(latestSchemaVersion)}
+	if !reflect.DeepEqual(migrated["a"], want) {
+		t.Errorf("got %v, want %v", migrated["a"], want)
+	}
+	if applied, _ := documents.AppliedVersion(); applied != latestSchemaVersion {
+		t.Errorf("got applied version %d, want %d", applied, latestSchemaVersion)
+	}
+	if !strings.Contains(out.String(), "1 of 2 webhooks changed") {
+		t.Errorf("got output %q, want 1 of 2 webhooks changed", out.String())
+	}
+
+	// a second run changes nothing
+	out.Reset()
+	if err := MigrateWebhooks(documents, 0, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	again, _ := documents.Documents()
+	if !reflect.DeepEqual(again, migrated) {
+		t.Errorf("second run changed the documents: got %v, want %v", again, migrated)
+	}
+	if !strings.Contains(out.String(), "0 of 2 webhooks changed") {
+		t.Errorf("got output %q from the second run, want 0 of 2 webhooks changed", out.String())
+	}
+}
+
+func TestMigrateWebhooksDryRun(t *testing.T) {
+	documents := newUnversionedDocuments()
+	before, _ := documents.Documents()
+	var out bytes.Buffer
+	if err := MigrateWebhooks(documents, 0, true, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "webhook a\n" +
+		"  - occurrences: 12 (int64)\n" +
+		"  + occurrences: 12 (float64)\n" +
+		"  + schema_version: 1 (int64)\n" +
+		"  - timeout: 60 (int64)\n" +
+		"  + timeout: 60 (float64)\n" +
+		"dry run: 1 of 2 webhooks would change to version 1\n"
+	if out.String() != want {
+		t.Errorf("got output\n%s\nwant\n%s", out.String(), want)
+	}
+	after, _ := documents.Documents()
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("dry run changed the documents: got %v, want %v", after, before)
+	}
+	if applied, _ := documents.AppliedVersion(); applied != 0 {
+		t.Errorf("dry run set the applied version to %d", applied)
+	}
+}
+
+func TestMigrateWebhooksVersionBounds(t *testing.T) {
+	for _, to := range []int{-1, latestSchemaVersion + 1} {
+		err := MigrateWebhooks(newUnversionedDocuments(), to, false, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), "should be from 1 to") {
+			t.Errorf("-to %d: got %v, want an invalid version error", to, err)
+		}
+	}
+
+	documents := newUnversionedDocuments()
+	documents.SetAppliedVersion(latestSchemaVersion + 1) // applied by a newer migrate command
+	err := MigrateWebhooks(documents, latestSchemaVersion, false, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "migrating back") {
+		t.Errorf("got %v, want an error for migrating back", err)
+	}
+}
+
+func TestMigrateWebhooksSkipsNewerVersions(t *testing.T) {
+	newer := map[string]interface{}{"url": "https://example.com/c", "timeout": int64(60), "schema_version": int64(latestSchemaVersion + 1)}
+	documents := NewMemoryDocuments(map[string]map[string]interface{}{"c": newer})
+	var out bytes.Buffer
+	if err := MigrateWebhooks(documents, 0, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	skipped := fmt.Sprintf("skipping webhook c: schema version %d is newer than %d", latestSchemaVersion+1, latestSchemaVersion)
+	if !strings.Contains(out.String(), skipped) {
+		t.Errorf("got output %q, want webhook c skipped", out.String())
+	}
+	after, _ := documents.Documents()
+	if !reflect.DeepEqual(after["c"], newer) {
+		t.Errorf("got %v, want the newer document unchanged", after["c"])
+	}
+}
+
+// documents that another writer changes after they are read, Replace fails as the firestore store does then
+type conflictingDocuments struct {
+	*MemoryDocuments
+}
+
+func (c conflictingDocuments) Replace(id string, data map[string]interface{}) error {
+	return errors.New("changed since it was read, run the migration again")
+}
+
+func TestMigrateWebhooksConflict(t *testing.T) {
+	documents := newUnversionedDocuments()
+	before, _ := documents.Documents()
+	err := MigrateWebhooks(conflictingDocuments{documents}, 0, false, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "webhook a: changed since it was read") {
+		t.Errorf("got %v, want the conflict for webhook a", err)
+	}
+	after, _ := documents.Documents()
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("got %v, want the documents unchanged", after)
+	}
+	if applied, _ := documents.AppliedVersion(); applied != 0 {
+		t.Errorf("got applied version %d after a conflict, want 0", applied)
+	}
+}
+
+// documents where change is run after they are read, before the migration replaces them
+type changedDocuments struct {
+	WebhookDocuments
+	change func()
+}
+
+func (c changedDocuments) Documents() (map[string]map[string]interface{}, error) {
+	all, err := c.WebhookDocuments.Documents()
+	c.change()
+	return all, err
+}
+
+// only runs against the firestore emulator, the webhooks collection must be empty and is left empty
+func TestFirestoreMigrateConflict(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	store, err := newFirestoreStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrationDoc := store.client.Collection("migrations").Doc("webhooks")
+	migrationDoc.Delete(store.ctx)
+	defer migrationDoc.Delete(store.ctx)
+	ref := store.client.Collection("webhooks").Doc("legacy")
+	defer ref.Delete(store.ctx)
+	_, err = ref.Set(store.ctx, map[string]interface{}{"url": "https://example.com/a", "timeout": int64(60), "occurrences": int64(12),
+		"field": "confirmed", "country": "Norway", "trigger": "ON_CHANGE", "time": time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the server notifies the webhook while the migration runs
+	updated := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	changed := changedDocuments{store, func() {
+		if err := store.Update("legacy", updated, 20); err != nil {
+			t.Fatal(err)
+		}
+	}}
+	err = MigrateWebhooks(changed, 0, false, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "webhook legacy: changed since it was read") {
+		t.Errorf("got %v, want the conflict for webhook legacy", err)
+	}
+	doc, err := ref.Get(store.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := doc.Data(); data["occurrences"] != 20.0 || data["schema_version"] != nil {
+		t.Errorf("got %v, want the update kept and the document not migrated", data)
+	}
+	if applied, err := store.AppliedVersion(); err != nil || applied != 0 {
+		t.Errorf("got applied version %d %v after a conflict, want 0", applied, err)
+	}
+
+	// running it again migrates the changed document
+	if err := MigrateWebhooks(store, 0, false, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	doc, err = ref.Get(store.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := doc.Data(); data["occurrences"] != 20.0 || data["timeout"] != 60.0 || data["schema_version"] != int64(latestSchemaVersion) {
+		t.Errorf("got %v, want the update kept and the document migrated", data)
+	}
+	if applied, err := store.AppliedVersion(); err != nil || applied != latestSchemaVersion {
+		t.Errorf("got applied version %d %v, want %d", applied, err, latestSchemaVersion)
+	}
+}
@@ -2,44 +2,51 @@ package CoronaAPI
 
 import (
 	"encoding/json"
-	"errors"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 )
 
 type Mmediagroup struct {
-	All map[string]interface{}
+	All    map[string]interface{}
+	source Source // where the data came from
 }
 
 // gets recovered data
 func getRecoveredData(countryName string, r *http.Request) (Mmediagroup, error) {
-	url := "https://covid-api.mmediagroup.fr/v1/history?country=" + countryName + "&status=Recovered"
-	recoveredData, err := getMmediagroupData(url)
+	recoveredData, err := getMmediagroupData(getHistoryUrl(countryName, "Recovered"))
 	return recoveredData, err
 }
 
 // gets confirmed data
 func getConfirmedData(countryName string) (Mmediagroup, error) {
-	url := "https://covid-api.mmediagroup.fr/v1/history?country=" + countryName + "&status=Confirmed"
-	recoveredData, err := getMmediagroupData(url)
+	recoveredData, err := getMmediagroupData(getHistoryUrl(countryName, "Confirmed"))
 	return recoveredData, err
 }
 
+// gets deaths data
+func getDeathsData(countryName string) (Mmediagroup, error) {
+	deathsData, err := getMmediagroupData(getHistoryUrl(countryName, "Deaths"))
+	return deathsData, err
+}
+
+// gets the url of the history of a status (Confirmed, Recovered or Deaths) in a country in the mmediagroup API
+// names like "United Kingdom" or "Korea, South" must be escaped, or mmediagroup answers 400
+func getHistoryUrl(countryName string, status string) string {
+	return "https://covid-api.mmediagroup.fr/v1/history?country=" + url.QueryEscape(countryName) + "&status=" + status
+}
+
 // gets confirmed/recovered data from mmediagroup API
-func getMmediagroupData(url string) (Mmediagroup, error) {
-	resp, err := http.Get(url)
+func getMmediagroupData(historyUrl string) (Mmediagroup, error) {
 	var mmediagroup Mmediagroup
+	body, source, err := getCachedResponse(historyUrl)
 	if err != nil {
-		return mmediagroup, errors.New("reponse error from mmediagroup (error with extern api)")
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return mmediagroup, errors.New("Error with ioutil.ReadAll (error with extern api)")
+		return mmediagroup, newUpstreamError("mmediagroup", err, "reponse error from mmediagroup (error with extern api)")
 	}
-	json.Unmarshal([]byte(string(body)), &mmediagroup)
+	json.Unmarshal(body, &mmediagroup)
+	mmediagroup.source = source
 
 	if len(mmediagroup.All) == 0 { // if country does not exist in external api
-		return mmediagroup, errors.New("Can't find country. Please check the spelling and try again")
+		return mmediagroup, newNotFoundError("unknown_country", "mmediagroup", "Can't find country. Please check the spelling and try again")
 	}
 	return mmediagroup, nil
 }
@@ -0,0 +1,47 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCountryWithSpaceInName(t *testing.T) {
+	useTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/history" && r.URL.Query().Get("country") == "United Kingdom":
+			w.Write(getTestHistory("United Kingdom", 66000000, 5))
+		case r.URL.Path == "/rest/v2/name/United Kingdom":
+			w.Write([]byte(`[{"name":"United Kingdom","alpha3Code":"GBR"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	cases, err := getCases("United Kingdom", "2021-02-01", "2021-03-01", resolveExact)
+	if err != nil {
+		t.Fatalf("getCases: %v", err)
+	}
+	if cases.Confirmed != 29*5 { // 5 new cases every day of february and on 1 march
+		t.Errorf("got %d confirmed, want %d", cases.Confirmed, 29*5)
+	}
+	code, _, err := getCountryCodeByName("United Kingdom")
+	if err != nil || code != "GBR" {
+		t.Errorf("getCountryCodeByName: got %q %v, want GBR", code, err)
+	}
+}
+
+// makes a mmediagroup history with perDay new cases every day of 2021-01-01 to 2021-03-31
+func getTestHistory(countryName string, population float64, perDay int) []byte {
+	dates := map[string]int{}
+	day := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; day.Month() <= time.March; i++ {
+		dates[day.Format(dateLayout)] = i * perDay
+		day = day.AddDate(0, 0, 1)
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"All": map[string]interface{}{"country": countryName, "population": population, "dates": dates},
+	})
+	return body
+}
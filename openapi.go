@@ -0,0 +1,277 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type openapiOperation struct {
+	path        string
+	method      string
+	summary     string
+	parameters  []string    // query parameters, path parameters are taken from path
+	requestBody interface{} // example value of the body type, nil if no body
+	response    interface{} // example value of the response type, nil if not json
+	contentType string      // content type if the response is not json
+}
+
+// every operation of the api, the schemas are made from the go types so they follow changes in the code
+var openapiOperations = []openapiOperation{
+	{path: "/corona/v1/country/{country_name}", method: "get", summary: "Confirmed and recovered cases in a country", parameters: []string{"scope", "resolve", "format", "fields"}, response: CasesPerCountry{}},
+	{path: "/corona/v1/country/{country_name}/regions", method: "get", summary: "Cases in every province or state of a country", parameters: []string{"scope", "resolve", "format"}, response: RegionsPerCountry{}},
+	{path: "/corona/v1/country/{country_name}/regions/{region_name}", method: "get", summary: "Cases in a province or state", parameters: []string{"scope", "resolve", "format"}, response: CasesPerRegion{}},
+	{path: "/corona/v1/country/{country_name}/timeseries", method: "get", summary: "Daily, weekly or monthly cases in a country", parameters: []string{"scope", "resolve", "granularity", "format"}, response: CasesTimeSeries{}},
+	{path: "/corona/v1/country/{country_name}/chart.svg", method: "get", summary: "Line chart or sparkline of a country", parameters: []string{"scope", "resolve", "granularity", "metric", "type", "width", "height"}, contentType: "image/svg+xml"},
+	{path: "/corona/v1/country/{country_name}/anomalies", method: "get", summary: "Outliers, negative corrections and stale periods in daily new cases", parameters: []string{"scope", "format"}, response: AnomalyReport{}},
+	{path: "/corona/v1/country/{country_name}/forecast", method: "get", summary: "Forecast of daily new cases", parameters: []string{"days", "format"}, response: Forecast{}},
+	{path: "/corona/v1/continent/{continent_name}", method: "get", summary: "Cases summed over all countries on a continent", parameters: []string{"scope", "resolve", "format"}, response: ContinentCases{}},
+	{path: "/corona/v1/policy/{country_name}", method: "get", summary: "Stringency and trend in a country", parameters: []string{"scope", "format", "fields"}, response: PolicyStringencyTrends{}},
+	{path: "/corona/v1/policy/{country_name}/timeseries", method: "get", summary: "Daily, weekly or monthly stringency in a country", parameters: []string{"scope", "granularity", "format"}, response: StringencyTimeSeries{}},
+	{path: "/corona/v1/ranking", method: "get", summary: "Countries ranked by a metric", parameters: []string{"metric", "scope", "resolve", "limit", "continent", "format"}, response: Ranking{}},
+	{path: "/corona/v1/badge/{country_name}.svg", method: "get", summary: "Status badge of a country", parameters: []string{"metric", "thresholds"}, contentType: "image/svg+xml"},
+	{path: "/corona/v1/analysis/{country_name}/policy-impact", method: "get", summary: "Correlation between stringency and later case growth", parameters: []string{"scope", "maxLag", "format"}, response: PolicyImpact{}},
+	{path: "/corona/v1/batch", method: "post", summary: "Runs many queries at once", requestBody: []BatchQuery{}, response: []BatchResult{}},
+	{path: "/corona/v1/notifications", method: "get", summary: "All registered webhooks", parameters: []string{"format", "fields"}, response: []WebhookRegistration{}},
+	{path: "/corona/v1/notifications", method: "post", summary: "Registers a webhook, responds with its id", requestBody: WebhookRegistration{}, contentType: "text/plain"},
+	{path: "/corona/v1/notifications/{id}", method: "get", summary: "A registered webhook", parameters: []string{"format", "fields"}, response: WebhookRegistration{}},
+	{path: "/corona/v1/notifications/{id}", method: "delete", summary: "Deletes a webhook", contentType: "text/plain"},
+	{path: "/corona/v1/diag", method: "get", summary: "Status of the service and the extern apis", response: Diag{}},
+	{path: "/corona/v1/openapi.json", method: "get", summary: "This document", contentType: "application/json"},
+	{path: "/corona/v1/docs", method: "get", summary: "Interactive documentation of this document", contentType: "text/html"},
+	{path: "/corona/graphql", method: "post", summary: "GraphQL endpoint over countries, cases, policy and webhooks", contentType: "application/json"},
+}
+
+// descriptions of the query parameters
+var openapiParameters = map[string]string{
+	"scope":       "Dates to use, like 2020-12-01-2021-01-31, 2020-12-01/2021-01-31, 2021-01-01/, last30d, P2W or 2021-Q1, the start and end date are both included",
+	"resolve":     "What to do when a date is missing in the data: exact, previous, next or clamp",
+	"format":      "Response format: json, csv or ndjson, can also be set with the Accept header",
+	"granularity": "day, week or month",
+	"metric":      "What to show or rank by",
+	"type":        "line or sparkline",
+	"width":       "Width in pixels, at least 120 for a line chart and 10 for a sparkline",
+	"height":      "Height in pixels, at least 100 for a line chart and 10 for a sparkline",
+	"days":        "Number of days",
+	"limit":       "Max number of countries",
+	"continent":   "Only countries on this continent",
+	"thresholds":  "Increasing numbers separated by comma where the colour changes",
+	"maxLag":      "Max number of days between stringency and case growth",
+	"fields":      "Only these fields in the response, separated by comma, like confirmed,population_percentage",
+}
+
+// gets every operation, the v2 operations are the same as in v1 except for the error responses
+func getOpenapiOperations() []openapiOperation {
+	operations := append([]openapiOperation{}, openapiOperations...)
+	for _, operation := range openapiOperations {
+		if !strings.HasPrefix(operation.path, "/corona/v1/") || operation.path == "/corona/v1/openapi.json" || operation.path == "/corona/v1/docs" {
+			continue
+		}
+		operation.path = strings.Replace(operation.path, "/corona/v1/", "/corona/v2/", 1)
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+var pathParameterRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+var openapiSpec = buildOpenapiSpec()
+
+// http://localhost:8080/corona/v1/openapi.json
+func HandleOpenapi(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		http.Header.Add(w.Header(), "content-type", "application/json")
+		json.NewEncoder(w).Encode(openapiSpec)
+		return
+	default:
+		return
+	}
+}
+
+// page that shows openapi.json with swagger ui
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>Corona API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@3/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "/corona/v1/openapi.json", dom_id: "#swagger-ui"})</script>
+</body>
+</html>
+`
+
+// http://localhost:8080/corona/v1/docs
+func HandleDocs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		http.Header.Add(w.Header(), "content-type", "text/html; charset=utf-8")
+		w.Write([]byte(docsPage))
+		return
+	default:
+		return
+	}
+}
+
+// builds the openapi 3 document from the operations
+func buildOpenapiSpec() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+	for _, operation := range getOpenapiOperations() {
+		var parameters []interface{}
+		for _, match := range pathParameterRegexp.FindAllStringSubmatch(operation.path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, name := range operation.parameters {
+			parameters = append(parameters, map[string]interface{}{
+				"name": name, "in": "query", "description": openapiParameters[name], "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+
+		response := map[string]interface{}{"description": "OK"}
+		if operation.response != nil {
+			response["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": getSchema(reflect.TypeOf(operation.response), schemas)},
+			}
+		} else if operation.contentType != "" {
+			response["content"] = map[string]interface{}{operation.contentType: map[string]interface{}{}}
+		}
+		responses := map[string]interface{}{"200": response}
+		if strings.HasPrefix(operation.path, "/corona/v2/") {
+			responses["default"] = map[string]interface{}{
+				"description": "Error, 404 when a country or other thing is unknown, 422 when the scope can't be used, 502 or 503 when an extern api fails and 504 when it times out",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": getSchema(reflect.TypeOf(apiError{}), schemas)},
+				},
+			}
+		} else {
+			responses["400"] = map[string]interface{}{"description": "Invalid request or error from an extern api"}
+		}
+		spec := map[string]interface{}{
+			"summary":   operation.summary,
+			"responses": responses,
+		}
+		if len(parameters) > 0 {
+			spec["parameters"] = parameters
+		}
+		if operation.requestBody != nil {
+			spec["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": getSchema(reflect.TypeOf(operation.requestBody), schemas)},
+				},
+			}
+		}
+
+		if paths[operation.path] == nil {
+			paths[operation.path] = map[string]interface{}{}
+		}
+		paths[operation.path].(map[string]interface{})[operation.method] = spec
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Corona API",
+			"version": "v1",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+// gets the json schema of a go type, named structs are added to schemas and referenced
+func getSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return getSchema(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": getSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": getSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" { // anonymous structs are written inline
+			return getStructSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = map[string]interface{}{} // placeholder in case the type refers to itself
+			schemas[t.Name()] = getStructSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{} // any value
+}
+
+// gets the properties of a struct the same way encoding/json writes them
+func getStructSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Name
+			tag := strings.Split(field.Tag.Get("json"), ",")[0]
+			if tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+				addFields(field.Type)
+				continue
+			}
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+			properties[name] = getSchema(field.Type, schemas)
+		}
+	}
+	addFields(t)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// checks that every route is in the spec and every operation in the spec has a route, routes are like "GET /corona/v1/ranking"
+func CheckOpenapiRoutes(routes []string) error {
+	var problems []string
+	operations := map[string]bool{}
+	for _, operation := range getOpenapiOperations() {
+		operations[strings.ToUpper(operation.method)+" "+operation.path] = true
+	}
+	for _, route := range routes {
+		if !operations[route] {
+			problems = append(problems, route+" has a handler but is not in the spec")
+		}
+		delete(operations, route)
+	}
+	for operation := range operations {
+		problems = append(problems, operation+" is in the spec but has no handler")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New("openapi spec and routes differ: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package CoronaAPI
+
+import (
+	"strings"
+	"testing"
+)
+
+// fails when a route is added without adding it to the spec, or the other way around
+func TestOpenapiMatchesRoutes(t *testing.T) {
+	router := NewApiRouter()
+	if err := CheckOpenapiRoutes(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckOpenapiRoutesFindsDrift(t *testing.T) {
+	routes := NewApiRouter().Routes()
+
+	err := CheckOpenapiRoutes(append(routes, "GET /corona/v1/unknown"))
+	if err == nil || !strings.Contains(err.Error(), "GET /corona/v1/unknown has a handler but is not in the spec") {
+		t.Fatalf("got %v, want the extra route reported", err)
+	}
+
+	err = CheckOpenapiRoutes(routes[1:])
+	if err == nil || !strings.Contains(err.Error(), routes[0]+" is in the spec but has no handler") {
+		t.Fatalf("got %v, want the missing route reported", err)
+	}
+}
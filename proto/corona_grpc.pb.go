@@ -0,0 +1,349 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package coronapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// CoronaClient is the client API for Corona service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CoronaClient interface {
+	// Confirmed and recovered cases in a country, like /corona/v1/country/{name}.
+	GetCases(ctx context.Context, in *CasesRequest, opts ...grpc.CallOption) (*Cases, error)
+	// Stringency and trend in a country, like /corona/v1/policy/{name}.
+	GetStringency(ctx context.Context, in *StringencyRequest, opts ...grpc.CallOption) (*StringencyTrend, error)
+	// Daily cases or stringency, one message per point.
+	StreamTimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (Corona_StreamTimeSeriesClient, error)
+	// Webhooks, like /corona/v1/notifications.
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	GetWebhook(ctx context.Context, in *GetWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+}
+
+type coronaClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCoronaClient(cc grpc.ClientConnInterface) CoronaClient {
+	return &coronaClient{cc}
+}
+
+func (c *coronaClient) GetCases(ctx context.Context, in *CasesRequest, opts ...grpc.CallOption) (*Cases, error) {
+	out := new(Cases)
+	err := c.cc.Invoke(ctx, "/corona.v1.Corona/GetCases", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coronaClient) GetStringency(ctx context.Context, in *StringencyRequest, opts ...grpc.CallOption) (*StringencyTrend, error) {
+	out := new(StringencyTrend)
+	err := c.cc.Invoke(ctx, "/corona.v1.Corona/GetStringency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coronaClient) StreamTimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (Corona_StreamTimeSeriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Corona_serviceDesc.Streams[0], "/corona.v1.Corona/StreamTimeSeries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coronaStreamTimeSeriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Corona_StreamTimeSeriesClient interface {
+	Recv() (*TimeSeriesPoint, error)
+	grpc.ClientStream
+}
+
+type coronaStreamTimeSeriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *coronaStreamTimeSeriesClient) Recv() (*TimeSeriesPoint, error) {
+	m := new(TimeSeriesPoint)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coronaClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, "/corona.v1.Corona/ListWebhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coronaClient) GetWebhook(ctx context.Context, in *GetWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/corona.v1.Corona/GetWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coronaClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/corona.v1.Corona/CreateWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coronaClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, "/corona.v1.Corona/DeleteWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoronaServer is the server API for Corona service.
+// All implementations must embed UnimplementedCoronaServer
+// for forward compatibility
+type CoronaServer interface {
+	// Confirmed and recovered cases in a country, like /corona/v1/country/{name}.
+	GetCases(context.Context, *CasesRequest) (*Cases, error)
+	// Stringency and trend in a country, like /corona/v1/policy/{name}.
+	GetStringency(context.Context, *StringencyRequest) (*StringencyTrend, error)
+	// Daily cases or stringency, one message per point.
+	StreamTimeSeries(*TimeSeriesRequest, Corona_StreamTimeSeriesServer) error
+	// Webhooks, like /corona/v1/notifications.
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	GetWebhook(context.Context, *GetWebhookRequest) (*Webhook, error)
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	mustEmbedUnimplementedCoronaServer()
+}
+
+// UnimplementedCoronaServer must be embedded to have forward compatible implementations.
+type UnimplementedCoronaServer struct {
+}
+
+func (UnimplementedCoronaServer) GetCases(context.Context, *CasesRequest) (*Cases, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCases not implemented")
+}
+func (UnimplementedCoronaServer) GetStringency(context.Context, *StringencyRequest) (*StringencyTrend, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStringency not implemented")
+}
+func (UnimplementedCoronaServer) StreamTimeSeries(*TimeSeriesRequest, Corona_StreamTimeSeriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTimeSeries not implemented")
+}
+func (UnimplementedCoronaServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedCoronaServer) GetWebhook(context.Context, *GetWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhook not implemented")
+}
+func (UnimplementedCoronaServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedCoronaServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedCoronaServer) mustEmbedUnimplementedCoronaServer() {}
+
+// UnsafeCoronaServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CoronaServer will
+// result in compilation errors.
+type UnsafeCoronaServer interface {
+	mustEmbedUnimplementedCoronaServer()
+}
+
+func RegisterCoronaServer(s grpc.ServiceRegistrar, srv CoronaServer) {
+	s.RegisterService(&_Corona_serviceDesc, srv)
+}
+
+func _Corona_GetCases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CasesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoronaServer).GetCases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/corona.v1.Corona/GetCases",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoronaServer).GetCases(ctx, req.(*CasesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Corona_GetStringency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StringencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoronaServer).GetStringency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/corona.v1.Corona/GetStringency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoronaServer).GetStringency(ctx, req.(*StringencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Corona_StreamTimeSeries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TimeSeriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoronaServer).StreamTimeSeries(m, &coronaStreamTimeSeriesServer{stream})
+}
+
+type Corona_StreamTimeSeriesServer interface {
+	Send(*TimeSeriesPoint) error
+	grpc.ServerStream
+}
+
+type coronaStreamTimeSeriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *coronaStreamTimeSeriesServer) Send(m *TimeSeriesPoint) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Corona_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoronaServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/corona.v1.Corona/ListWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoronaServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Corona_GetWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoronaServer).GetWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/corona.v1.Corona/GetWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoronaServer).GetWebhook(ctx, req.(*GetWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Corona_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoronaServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/corona.v1.Corona/CreateWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoronaServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Corona_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoronaServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/corona.v1.Corona/DeleteWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoronaServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Corona_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "corona.v1.Corona",
+	HandlerType: (*CoronaServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCases",
+			Handler:    _Corona_GetCases_Handler,
+		},
+		{
+			MethodName: "GetStringency",
+			Handler:    _Corona_GetStringency_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Corona_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "GetWebhook",
+			Handler:    _Corona_GetWebhook_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _Corona_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _Corona_DeleteWebhook_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTimeSeries",
+			Handler:       _Corona_StreamTimeSeries_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "corona.proto",
+}
@@ -0,0 +1,62 @@
+package CoronaAPI
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// where the numbers in a response come from and how old they are
+type Metadata struct {
+	Sources   []Source
+	Data_date string // newest date in the data that is used, which can be older than the scope asked for
+}
+
+// a single request to an extern api
+type Source struct {
+	Name    string // mmediagroup, covidtracker or restcountries
+	Url     string
+	Fetched time.Time // when the data was fetched from the extern api
+	Cached  bool      // if the data came from the cache instead of a new request
+}
+
+// gets the name of the extern api of url
+func getSourceName(url string) string {
+	for _, name := range []string{"mmediagroup", "covidtracker", "restcountries"} {
+		if strings.Contains(url, name) {
+			return name
+		}
+	}
+	return url
+}
+
+// makes the metadata of a response, sources with the same url are only listed once
+func newMetadata(dataDate string, sources ...Source) *Metadata {
+	metadata := &Metadata{Data_date: dataDate}
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if source.Url == "" || seen[source.Url] {
+			continue
+		}
+		seen[source.Url] = true
+		metadata.Sources = append(metadata.Sources, source)
+	}
+	sort.Slice(metadata.Sources, func(i, j int) bool { return metadata.Sources[i].Url < metadata.Sources[j].Url })
+	return metadata
+}
+
+// combines the metadata of many responses, the data date is the newest one
+func mergeMetadata(all ...*Metadata) *Metadata {
+	var sources []Source
+	dataDate := ""
+	for _, metadata := range all {
+		if metadata == nil {
+			continue
+		}
+		sources = append(sources, metadata.Sources...)
+		if metadata.Data_date > dataDate {
+			dataDate = metadata.Data_date
+		}
+	}
+	return newMetadata(dataDate, sources...)
+}
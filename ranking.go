@@ -0,0 +1,266 @@
+package CoronaAPI
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type Ranking struct {
+	Metric    string
+	Scope     string
+	Continent string
+	Countries []CountryRank
+	Missing   []string  // countries without a value in the scope, like when an extern api fails for them
+	Metadata  *Metadata `json:",omitempty"` // for all countries in both periods
+}
+
+type CountryRank struct {
+	Rank          int
+	Country       string
+	Continent     string
+	Value         float64
+	Previous_rank int
+	Movement      int
+}
+
+// number of days ranked when no scope is given
+const defaultRankingDays = 14
+
+// number of countries in the ranking when no limit is given
+const defaultRankingLimit = 20
+
+// http://localhost:8080/corona/v1/ranking{?metric=confirmed|incidence|per-capita|growth|stringency&scope=begin_date-end_date&limit=number&continent=continent_name}
+func HandleRanking(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		http.Header.Add(w.Header(), "content-type", "application/json")
+
+		metric := r.URL.Query().Get("metric")
+		if metric == "" {
+			metric = "confirmed"
+		}
+		if !isValidRankingMetric(metric) {
+			writeError(w, r, errors.New("Invalid metric, should be one of: confirmed, incidence, per-capita, growth, stringency"))
+			return
+		}
+
+		limit := defaultRankingLimit
+		if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
+			var err error
+			limit, err = strconv.Atoi(limitQuery)
+			if err != nil || limit < 1 {
+				writeError(w, r, errors.New("Invalid limit, should be a positive number"))
+				return
+			}
+		}
+
+		startDate, endDate, err := getUrlScope(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		resolution, err := getDateResolution(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response, err := getRanking(metric, startDate, endDate, r.URL.Query().Get("continent"), limit, resolution)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// checks if the metric can be ranked
+func isValidRankingMetric(metric string) bool {
+	switch metric {
+	case "confirmed", "incidence", "per-capita", "growth", "stringency":
+		return true
+	}
+	return false
+}
+
+// gets the period of the same length right before the scope, both include their start and end date
+func getPreviousPeriod(startDate string, endDate string) (string, string, error) {
+	start, err := parseScopeDate(startDate, "start")
+	if err != nil {
+		return "", "", err
+	}
+	end, err := parseScopeDate(endDate, "end")
+	if err != nil {
+		return "", "", err
+	}
+	previousEnd := start.AddDate(0, 0, -1)
+	return previousEnd.Add(-end.Sub(start)).Format(dateLayout), previousEnd.Format(dateLayout), nil
+}
+
+// gets the value a country is ranked by in the scope, and the metadata of the data it's made from
+func getMetricValue(country Country, metric string, startDate string, endDate string, resolution string) (float64, *Metadata, error) {
+	switch metric {
+	case "stringency":
+		countryCode, codeSource, err := getCountryCodeByName(country.Name)
+		if err != nil {
+			return 0, nil, err
+		}
+		stringencyData, err := getStringencyData(countryCode, endDate)
+		if err != nil {
+			return 0, nil, err
+		}
+		return stringencyData.StringencyData.Stringency, newMetadata(stringencyData.StringencyData.Date_value, codeSource, stringencyData.source), nil
+	case "growth":
+		// growth in percent of new cases compared to the period before
+		previousStart, previousEnd, err := getPreviousPeriod(startDate, endDate)
+		if err != nil {
+			return 0, nil, err
+		}
+		current, currentMetadata, err := getMetricValue(country, "confirmed", startDate, endDate, resolution)
+		if err != nil {
+			return 0, nil, err
+		}
+		previous, previousMetadata, err := getMetricValue(country, "confirmed", previousStart, previousEnd, resolution)
+		if err != nil {
+			return 0, nil, err
+		}
+		if previous == 0 {
+			return 0, nil, errors.New("No cases in the previous period for " + country.Name)
+		}
+		return (current - previous) / previous * 100, mergeMetadata(currentMetadata, previousMetadata), nil
+	}
+
+	confirmedData, err := getConfirmedData(country.Name)
+	if err != nil {
+		return 0, nil, err
+	}
+	confirmedDates := getDates(confirmedData)
+	usedStart, usedEnd, err := resolveScope(confirmedDates, startDate, endDate, resolution)
+	if err != nil {
+		return 0, nil, err
+	}
+	confirmed, err := getScopedValue(confirmedDates, usedStart, usedEnd, "")
+	if err != nil {
+		return 0, nil, err
+	}
+	metadata := newMetadata(usedEnd, confirmedData.source)
+	if metric == "incidence" || metric == "per-capita" { // new cases per 100 000 inhabitants
+		if country.Population == 0 {
+			return 0, nil, errors.New("Missing population for " + country.Name)
+		}
+		return confirmed / country.Population * 100000, metadata, nil
+	}
+	return confirmed, metadata, nil
+}
+
+// ranks countries by value, countries without a value are left out
+func rankCountries(countries []Country, values []float64, errs []error) []CountryRank {
+	var ranks []CountryRank
+	for i, country := range countries {
+		if errs[i] != nil {
+			continue
+		}
+		var rank CountryRank
+		rank.Country = country.Name
+		rank.Continent = country.Continent
+		rank.Value = values[i]
+		ranks = append(ranks, rank)
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return ranks[i].Value > ranks[j].Value })
+	for i := range ranks {
+		rank := i + 1
+		if i > 0 && ranks[i].Value == ranks[i-1].Value { // equal values share the rank
+			rank = ranks[i-1].Rank
+		}
+		ranks[i].Rank = rank
+	}
+	return ranks
+}
+
+// ranks all countries, or the countries on a continent, by metric in the scope and the period before
+func getRanking(metric string, startDate string, endDate string, continent string, limit int, resolution string) (Ranking, error) {
+	var response Ranking
+	var countries []Country
+	if continent != "" {
+		var err error
+		countries, err = getCountriesByContinent(continent)
+		if err != nil {
+			return response, err
+		}
+	} else {
+		registry, err := getCountryRegistry()
+		if err != nil {
+			return response, err
+		}
+		for _, country := range registry {
+			countries = append(countries, country)
+		}
+		sort.Slice(countries, func(i, j int) bool { return countries[i].Name < countries[j].Name })
+	}
+
+	if startDate == "" { // if not using scope, ranks the last days with data
+		confirmedData, err := getConfirmedData(countries[0].Name)
+		if err != nil {
+			return response, err
+		}
+		latest, err := time.Parse(dateLayout, getLatestDate(getDates(confirmedData)))
+		if err != nil {
+			return response, newUpstreamError("mmediagroup", nil, "Can't find the newest date in data from mmediagroup (error with extern api)")
+		}
+		endDate = latest.Format(dateLayout)
+		startDate = latest.AddDate(0, 0, -defaultRankingDays+1).Format(dateLayout)
+	}
+	previousStart, previousEnd, err := getPreviousPeriod(startDate, endDate)
+	if err != nil {
+		return response, err
+	}
+
+	// fetches every country at the same time, for both periods
+	values := make([]float64, len(countries))
+	errs := make([]error, len(countries))
+	previousValues := make([]float64, len(countries))
+	previousErrs := make([]error, len(countries))
+	metadata := make([]*Metadata, 2*len(countries))
+	fetchConcurrently(len(countries), func(i int) {
+		values[i], metadata[2*i], errs[i] = getMetricValue(countries[i], metric, startDate, endDate, resolution)
+		previousValues[i], metadata[2*i+1], previousErrs[i] = getMetricValue(countries[i], metric, previousStart, previousEnd, resolution)
+	})
+
+	ranks := rankCountries(countries, values, errs)
+	if len(ranks) == 0 {
+		return response, errs[0]
+	}
+	for i, country := range countries {
+		if errs[i] != nil { // countries without a value are listed, not ranked
+			response.Missing = append(response.Missing, country.Name)
+		}
+	}
+	previousRanks := map[string]int{}
+	for _, rank := range rankCountries(countries, previousValues, previousErrs) {
+		previousRanks[rank.Country] = rank.Rank
+	}
+	for i := range ranks {
+		if previous, ok := previousRanks[ranks[i].Country]; ok {
+			ranks[i].Previous_rank = previous
+			ranks[i].Movement = previous - ranks[i].Rank // positive when moving up
+		}
+	}
+	if len(ranks) > limit {
+		ranks = ranks[:limit]
+	}
+
+	response.Metric = metric
+	response.Scope = getScopeName(startDate, endDate)
+	response.Continent = continent
+	response.Countries = ranks
+	response.Metadata = mergeMetadata(metadata...)
+	return response, nil
+}
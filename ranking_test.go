@@ -0,0 +1,44 @@
+package CoronaAPI
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRankingWithMultiWordCountry(t *testing.T) {
+	useTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.Query().Get("country") {
+		case "/v1/cases?":
+			w.Write([]byte(`{
+				"Norway": {"All": {"continent": "Europe", "population": 5000000}},
+				"United Kingdom": {"All": {"continent": "Europe", "population": 66000000}},
+				"Atlantis": {"All": {"continent": "Europe", "population": 1000}},
+				"Japan": {"All": {"continent": "Asia", "population": 126000000}}
+			}`))
+		case "/v1/history?Norway":
+			w.Write(getTestHistory("Norway", 5000000, 1))
+		case "/v1/history?United Kingdom":
+			w.Write(getTestHistory("United Kingdom", 66000000, 5))
+		case "/v1/history?Atlantis":
+			w.Write([]byte(`{}`)) // mmediagroup has no data for it
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	ranking, err := getRanking("confirmed", "2021-02-01", "2021-03-01", "Europe", 10, resolveExact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var countries []string
+	for _, rank := range ranking.Countries {
+		countries = append(countries, rank.Country)
+	}
+	if !reflect.DeepEqual(countries, []string{"United Kingdom", "Norway"}) {
+		t.Errorf("got ranking %v, want United Kingdom, Norway", countries)
+	}
+	if !reflect.DeepEqual(ranking.Missing, []string{"Atlantis"}) {
+		t.Errorf("got missing %v, want Atlantis", ranking.Missing)
+	}
+}
@@ -0,0 +1,179 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type CasesPerRegion struct {
+	Country    string
+	Region     string
+	Scope      string
+	Confirmed  int
+	Recovered  int
+	Start_date string    `json:",omitempty"`
+	End_date   string    `json:",omitempty"`
+	Metadata   *Metadata `json:",omitempty"`
+}
+
+type RegionsPerCountry struct {
+	Country           string
+	Scope             string
+	Regions           []CasesPerRegion
+	Missing           []string  // regions without data in the scope
+	Without_recovered []string  // regions that don't report recovered, they have 0 recovered
+	Metadata          *Metadata `json:",omitempty"` // for all regions, they come from the same requests
+}
+
+// http://localhost:8080/corona/v1/country/{:country_name}/regions{/:region_name}{?scope=begin_date-end_date}
+func HandleRegions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		http.Header.Add(w.Header(), "content-type", "application/json")
+
+		// gets information in url parameter
+		countryName, startDate, endDate, err := getUrlData("country_name", r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		regionName := pathParam(r, "region_name") // empty when all regions are asked for
+		resolution, err := getDateResolution(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response, err := getRegionCases(countryName, regionName, startDate, endDate, resolution)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if regionName != "" { // if a single region
+			region := response.Regions[0]
+			region.Metadata = response.Metadata
+			writeResponse(w, r, region)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// gets the data of every region (province/state) in a country, "All" is left out
+func getMmediagroupRegions(url string) (map[string]Mmediagroup, error) {
+	body, source, err := getCachedResponse(url)
+	if err != nil {
+		return nil, newUpstreamError("mmediagroup", err, "reponse error from mmediagroup (error with extern api)")
+	}
+	var allData map[string]map[string]interface{}
+	json.Unmarshal(body, &allData)
+	if len(allData) == 0 { // if country does not exist in external api
+		return nil, newNotFoundError("unknown_country", "mmediagroup", "Can't find country. Please check the spelling and try again")
+	}
+
+	regions := map[string]Mmediagroup{}
+	for name, data := range allData {
+		if name == "All" {
+			continue
+		}
+		regions[name] = Mmediagroup{All: data, source: source}
+	}
+	return regions, nil
+}
+
+// gets confirmed and recovered cases for every region in a country sorted by name, or only for regionName if not empty
+// regions that fail are listed in Missing, a single region that fails is an error
+func getRegionCases(countryName string, regionName string, startDate string, endDate string, resolution string) (RegionsPerCountry, error) {
+	var response RegionsPerCountry
+	confirmedRegions, err := getMmediagroupRegions(getHistoryUrl(countryName, "Confirmed"))
+	if err != nil {
+		return response, err
+	}
+	recoveredRegions, err := getMmediagroupRegions(getHistoryUrl(countryName, "Recovered"))
+	if err != nil {
+		return response, err
+	}
+	if len(confirmedRegions) == 0 {
+		return response, newNotFoundError("unknown_region", "mmediagroup", "No regional data for "+countryName)
+	}
+
+	var names []string
+	for name := range confirmedRegions {
+		if regionName == "" || strings.EqualFold(name, regionName) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return response, newNotFoundError("unknown_region", "mmediagroup", "Can't find region "+regionName+" in "+countryName)
+	}
+	sort.Strings(names)
+
+	response.Country = countryName
+	response.Scope = getScopeName(startDate, endDate)
+	var metadata []*Metadata
+	var regionErr error
+	for _, name := range names {
+		region, hasRecovered, err := getRegion(countryName, name, confirmedRegions[name], recoveredRegions[name], startDate, endDate, resolution)
+		if err != nil {
+			regionErr = fmt.Errorf("%s: %w", name, err)
+			response.Missing = append(response.Missing, name)
+			continue
+		}
+		if !hasRecovered {
+			response.Without_recovered = append(response.Without_recovered, name)
+		}
+		metadata = append(metadata, region.Metadata)
+		region.Metadata = nil // listed once for the country
+		response.Regions = append(response.Regions, region)
+	}
+	if len(response.Regions) == 0 { // if no region has data, the error is most likely the same for all
+		return response, regionErr
+	}
+	response.Metadata = mergeMetadata(metadata...)
+	return response, nil
+}
+
+// gets confirmed and recovered cases for a region, and if it reports recovered
+func getRegion(countryName string, name string, confirmedData Mmediagroup, recoveredData Mmediagroup, startDate string, endDate string, resolution string) (CasesPerRegion, bool, error) {
+	var region CasesPerRegion
+	confirmedDates := getDates(confirmedData)
+	latestDate := getLatestDate(confirmedDates)
+	usedStart, usedEnd, err := resolveScope(confirmedDates, startDate, endDate, resolution)
+	if err != nil {
+		return region, false, err
+	}
+	confirmed, err := getScopedValue(confirmedDates, usedStart, usedEnd, latestDate)
+	if err != nil {
+		return region, false, err
+	}
+	// some regions don't report recovered at all, they get 0
+	var recovered float64
+	recoveredDates := getDates(recoveredData)
+	hasRecovered := len(recoveredDates) > 0
+	if hasRecovered {
+		recovered, err = getScopedValue(recoveredDates, usedStart, usedEnd, latestDate)
+		if err != nil {
+			return region, false, err
+		}
+	}
+
+	region.Country = countryName
+	region.Region = name
+	region.Scope = getScopeName(startDate, endDate)
+	region.Confirmed = int(confirmed)
+	region.Recovered = int(recovered)
+	region.Start_date = usedStart
+	region.End_date = usedEnd
+	if usedEnd == "" {
+		region.End_date = latestDate
+	}
+	region.Metadata = newMetadata(region.End_date, confirmedData.source, recoveredData.source)
+	return region, hasRecovered, nil
+}
@@ -0,0 +1,81 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Country struct {
+	Name         string
+	Continent    string
+	Abbreviation string
+	Population   float64
+}
+
+// max number of requests sent to an extern api at the same time
+const maxConcurrentFetches = 8
+
+// gets every country mmediagroup has data on, with name as key
+func getCountryRegistry() (map[string]Country, error) {
+	body, err := getCachedBody("https://covid-api.mmediagroup.fr/v1/cases")
+	if err != nil {
+		return nil, newUpstreamError("mmediagroup", err, "reponse error from mmediagroup (error with extern api)")
+	}
+	var allCases map[string]Mmediagroup
+	json.Unmarshal(body, &allCases)
+	if len(allCases) == 0 {
+		return nil, newUpstreamError("mmediagroup", nil, "Can't get the list of countries from mmediagroup (error with extern api)")
+	}
+
+	registry := map[string]Country{}
+	for name, data := range allCases {
+		continent, _ := data.All["continent"].(string)
+		if continent == "" { // skips entries that are not countries, like cruise ships
+			continue
+		}
+		var country Country
+		country.Name = name
+		country.Continent = continent
+		country.Abbreviation, _ = data.All["abbreviation"].(string)
+		country.Population, _ = data.All["population"].(float64)
+		registry[name] = country
+	}
+	return registry, nil
+}
+
+// gets all countries on a continent, sorted by name
+func getCountriesByContinent(continent string) ([]Country, error) {
+	registry, err := getCountryRegistry()
+	if err != nil {
+		return nil, err
+	}
+	var countries []Country
+	for _, country := range registry {
+		if strings.EqualFold(country.Continent, continent) {
+			countries = append(countries, country)
+		}
+	}
+	if len(countries) == 0 {
+		return nil, newNotFoundError("unknown_continent", "mmediagroup", "Can't find continent with name: "+continent)
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].Name < countries[j].Name })
+	return countries, nil
+}
+
+// runs fetch for every index in 0..count, at most maxConcurrentFetches at the same time
+func fetchConcurrently(count int, fetch func(i int)) {
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, maxConcurrentFetches)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			fetch(i)
+			<-workers
+		}(i)
+	}
+	wg.Wait()
+}
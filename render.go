@@ -0,0 +1,332 @@
+package CoronaAPI
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// responses that are more than one row in csv and ndjson, like time series
+type rowsProvider interface {
+	rows() interface{} // a slice with an element per row, typed so csv has a header without rows
+}
+
+type column struct {
+	name  string
+	value string
+}
+
+// media types of each response format, in the order used when the Accept header likes them the same
+var formatMediaTypes = []struct {
+	format    string
+	mediaType string
+}{
+	{"json", "application/json"},
+	{"csv", "text/csv"},
+	{"ndjson", "application/x-ndjson"},
+	{"ndjson", "application/ndjson"},
+}
+
+// gets the response format from ?format, or from the Accept header if not in url
+// an unknown ?format is a bad request, an Accept header without a format we have is not acceptable
+func getResponseFormat(r *http.Request) (string, error) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		switch format {
+		case "json", "csv", "ndjson":
+			return format, nil
+		}
+		return "", errors.New("Invalid format '" + format + "', should be one of: json, csv, ndjson")
+	}
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return "json", nil
+	}
+
+	// the quality of a media type comes from the most specific range that matches it
+	bestFormat, bestQuality := "", 0.0
+	for _, candidate := range formatMediaTypes {
+		quality, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaRange, rangeQuality := parseMediaRange(part)
+			if rangeSpecificity := getMediaRangeSpecificity(mediaRange, candidate.mediaType); rangeSpecificity > specificity {
+				quality, specificity = rangeQuality, rangeSpecificity
+			}
+		}
+		if quality > bestQuality { // the first format wins when they are liked the same
+			bestFormat, bestQuality = candidate.format, quality
+		}
+	}
+	if bestFormat == "" {
+		return "", &apiError{Code: "not_acceptable", Message: "Can't respond with " + accept + ", should accept one of: application/json, text/csv, application/x-ndjson", Status: http.StatusNotAcceptable}
+	}
+	return bestFormat, nil
+}
+
+// parses a part of an Accept header, like "text/csv;q=0.5", into the media range and its quality (1 if not set)
+func parseMediaRange(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+	quality := 1.0
+	for _, param := range params[1:] {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				return mediaRange, 0 // a broken quality is left out
+			}
+			quality = parsed
+		}
+	}
+	return mediaRange, quality
+}
+
+// gets how specific a media range is for a media type, 2 for an exact match, 1 for type/*, 0 for */*, -1 if no match
+func getMediaRangeSpecificity(mediaRange string, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
+
+// writes the response as json, csv or ndjson, with an etag
+func writeResponse(w http.ResponseWriter, r *http.Request, response interface{}) {
+	format, err := getResponseFormat(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var body bytes.Buffer
+	switch format {
+	case "csv":
+		w.Header().Set("content-type", "text/csv")
+		writeCsv(&body, getRows(response))
+	case "ndjson":
+		w.Header().Set("content-type", "application/x-ndjson")
+		encoder := json.NewEncoder(&body) // Encode adds a newline after each row
+		rows := reflect.ValueOf(getRows(response))
+		for i := 0; i < rows.Len(); i++ {
+			encoder.Encode(rows.Index(i).Interface())
+		}
+	default:
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(&body).Encode(response)
+	}
+	writeConditional(w, r, format, body.Bytes(), response)
+}
+
+// gets the rows of a response as a slice, a slice is a row per element and anything else a single row
+func getRows(response interface{}) interface{} {
+	if provider, ok := response.(rowsProvider); ok {
+		return provider.rows()
+	}
+	value := reflect.ValueOf(response)
+	if value.Kind() == reflect.Slice {
+		return response
+	}
+	rows := reflect.MakeSlice(reflect.SliceOf(value.Type()), 1, 1)
+	rows.Index(0).Set(value)
+	return rows.Interface()
+}
+
+// writes rows, a slice, as csv with a header, the columns are taken from the type of the rows so there is
+// always a header, also without rows
+func writeCsv(w io.Writer, rows interface{}) {
+	writer := csv.NewWriter(w)
+	value := reflect.ValueOf(rows)
+	rowType := value.Type().Elem()
+	if rowType.Kind() == reflect.Interface && value.Len() > 0 { // untyped rows, uses the first
+		rowType = value.Index(0).Elem().Type()
+	}
+	writer.Write(getColumnNames(rowType))
+	for i := 0; i < value.Len(); i++ {
+		var record []string
+		for _, c := range getColumns(value.Index(i)) {
+			record = append(record, c.value)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+}
+
+// gets the names of the columns of a row type, the same as getColumns gives for a row
+func getColumnNames(rowType reflect.Type) []string {
+	if rowType.Kind() == reflect.Ptr {
+		rowType = rowType.Elem()
+	}
+	if rowType.Kind() != reflect.Struct {
+		return []string{"value"}
+	}
+	var names []string
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			names = append(names, getColumnNames(field.Type)...)
+			continue
+		}
+		if kind := field.Type.Kind(); kind == reflect.Slice || kind == reflect.Map || kind == reflect.Ptr {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// gets the fields of a struct as columns, named as in json, embedded structs are flattened and slices, maps and pointers left out
+func getColumns(value reflect.Value) []column {
+	if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return []column{{name: "value", value: formatValue(value)}}
+	}
+	var columns []column
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fieldValue := value.Field(i)
+		if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+			columns = append(columns, getColumns(fieldValue)...)
+			continue
+		}
+		if fieldValue.Kind() == reflect.Slice || fieldValue.Kind() == reflect.Map || fieldValue.Kind() == reflect.Ptr {
+			continue
+		}
+		columns = append(columns, column{name: name, value: formatValue(fieldValue)})
+	}
+	return columns
+}
+
+// formats a single value for csv
+func formatValue(value reflect.Value) string {
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.String:
+		return value.String()
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// a row per point, with the country on every row
+func (t CasesTimeSeries) rows() interface{} {
+	type row struct {
+		Country     string
+		Granularity string
+		CasesPoint
+	}
+	rows := []row{}
+	for _, point := range t.Series {
+		rows = append(rows, row{t.Country, t.Granularity, point})
+	}
+	return rows
+}
+
+// a row per point, with the country on every row
+func (t StringencyTimeSeries) rows() interface{} {
+	type row struct {
+		Country     string
+		Granularity string
+		StringencyPoint
+	}
+	rows := []row{}
+	for _, point := range t.Series {
+		rows = append(rows, row{t.Country, t.Granularity, point})
+	}
+	return rows
+}
+
+// a row per anomaly, with the country on every row
+func (report AnomalyReport) rows() interface{} {
+	type row struct {
+		Country string
+		Anomaly
+	}
+	rows := []row{}
+	for _, anomaly := range report.Anomalies {
+		rows = append(rows, row{report.Country, anomaly})
+	}
+	return rows
+}
+
+// a row per forecast day, with the country on every row
+func (forecast Forecast) rows() interface{} {
+	type row struct {
+		Country string
+		ForecastPoint
+	}
+	rows := []row{}
+	for _, point := range forecast.Forecast {
+		rows = append(rows, row{forecast.Country, point})
+	}
+	return rows
+}
+
+// a row per lag, with the country on every row
+func (impact PolicyImpact) rows() interface{} {
+	type row struct {
+		Country string
+		LagCorrelation
+	}
+	rows := []row{}
+	for _, correlation := range impact.Correlations {
+		rows = append(rows, row{impact.Country, correlation})
+	}
+	return rows
+}
+
+// a row per country, they have the continent on every row
+func (cases ContinentCases) rows() interface{} {
+	return cases.Countries
+}
+
+// a row per region, they have the country on every row
+func (regions RegionsPerCountry) rows() interface{} {
+	return regions.Regions
+}
+
+// a row per country, with the metric and scope on every row
+func (ranking Ranking) rows() interface{} {
+	type row struct {
+		Metric string
+		Scope  string
+		CountryRank
+	}
+	rows := []row{}
+	for _, rank := range ranking.Countries {
+		rows = append(rows, row{ranking.Metric, ranking.Scope, rank})
+	}
+	return rows
+}
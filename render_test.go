@@ -0,0 +1,33 @@
+package CoronaAPI
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCsvHeaderWithoutRows(t *testing.T) {
+	store = newMemoryStore()
+	router := NewApiRouter()
+
+	tests := []struct {
+		url    string
+		header string
+	}{
+		{"/corona/v1/notifications?format=csv", "ID,url,timeout,time,field,country,trigger,occurrences"},
+		{"/corona/v1/notifications?format=csv&fields=country,url", "country,url"},
+	}
+	for _, test := range tests {
+		response := serveTest(router, http.MethodGet, test.url)
+		if response.Code != http.StatusOK || strings.TrimSpace(response.Body.String()) != test.header {
+			t.Errorf("%s: got %d %q, want 200 with only the header %q", test.url, response.Code, response.Body.String(), test.header)
+		}
+	}
+
+	series := CasesTimeSeries{Country: "Norway", Granularity: "daily"}
+	var body strings.Builder
+	writeCsv(&body, getRows(series))
+	if !strings.HasPrefix(body.String(), "Country,Granularity,") || strings.Count(body.String(), "\n") != 1 {
+		t.Errorf("time series without points: got %q, want only the header", body.String())
+	}
+}
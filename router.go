@@ -0,0 +1,170 @@
+package CoronaAPI
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// routes requests by method and path, paths can have parameters like /country/{country_name}
+type Router struct {
+	routes   []*route
+	notFound http.HandlerFunc
+}
+
+type route struct {
+	pattern  string
+	segments []routeSegment
+	handlers map[string]http.HandlerFunc // handler per method
+}
+
+// a part of a path between two '/', a parameter can have text around it like {country_name}.svg
+type routeSegment struct {
+	prefix string
+	param  string // empty if the segment is only text
+	suffix string
+}
+
+type pathParamsKey struct{}
+
+// makes a router, notFound handles paths without a route
+func NewRouter(notFound http.HandlerFunc) *Router {
+	return &Router{notFound: notFound}
+}
+
+// adds a handler for method on pattern
+func (router *Router) Handle(method string, pattern string, handler http.HandlerFunc) {
+	pattern = normalisePath(pattern)
+	for _, existing := range router.routes {
+		if existing.pattern == pattern {
+			existing.handlers[method] = handler
+			return
+		}
+	}
+	var segments []routeSegment
+	for _, part := range strings.Split(strings.TrimPrefix(pattern, "/"), "/") {
+		segment := routeSegment{prefix: part}
+		if start, end := strings.Index(part, "{"), strings.Index(part, "}"); start >= 0 && end > start {
+			segment = routeSegment{prefix: part[:start], param: part[start+1 : end], suffix: part[end+1:]}
+		}
+		segments = append(segments, segment)
+	}
+	router.routes = append(router.routes, &route{pattern: pattern, segments: segments, handlers: map[string]http.HandlerFunc{method: handler}})
+}
+
+// gets every route as "METHOD pattern", sorted
+func (router *Router) Routes() []string {
+	var routes []string
+	for _, route := range router.routes {
+		for method := range route.handlers {
+			routes = append(routes, method+" "+route.pattern)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, params := router.match(normalisePath(r.URL.Path))
+	if route == nil {
+		router.notFound(w, r)
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, params))
+
+	handler, ok := route.handlers[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		// head is a get without the body, the handlers only answer get so the request is made one
+		if handler, ok = route.handlers[http.MethodGet]; ok {
+			w = headResponseWriter{w}
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+	}
+	if ok {
+		handler(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(route.allowedMethods(), ", "))
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	message := "Method " + r.Method + " is not allowed, use one of: " + strings.Join(route.allowedMethods(), ", ")
+	if getApiVersion(r) == "v2" {
+		writeError(w, r, &apiError{Code: "method_not_allowed", Message: message, Status: http.StatusMethodNotAllowed})
+		return
+	}
+	http.Error(w, message, http.StatusMethodNotAllowed)
+}
+
+// gets the route matching path and the values of its parameters, nil if there is none
+func (router *Router) match(path string) (*route, map[string]string) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for _, route := range router.routes {
+		if len(route.segments) != len(parts) {
+			continue
+		}
+		params := map[string]string{}
+		matches := true
+		for i, segment := range route.segments {
+			part := parts[i]
+			if segment.param == "" {
+				matches = part == segment.prefix
+			} else {
+				matches = len(part) > len(segment.prefix)+len(segment.suffix) &&
+					strings.HasPrefix(part, segment.prefix) && strings.HasSuffix(part, segment.suffix)
+				if matches {
+					params[segment.param] = part[len(segment.prefix) : len(part)-len(segment.suffix)]
+				}
+			}
+			if !matches {
+				break
+			}
+		}
+		if matches {
+			return route, params
+		}
+	}
+	return nil, nil
+}
+
+// gets the methods the route answers, options is always there and head when there is a get
+func (route *route) allowedMethods() []string {
+	methods := []string{http.MethodOptions}
+	for method := range route.handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := route.handlers[http.MethodGet]; ok {
+		if _, ok := route.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// removes a trailing '/', so /country/norway/ is the same as /country/norway
+func normalisePath(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// gets a path parameter of the route that matched the request
+func pathParam(r *http.Request, name string) string {
+	params, _ := r.Context().Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
+
+// writes headers but not the body, for head requests
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(body []byte) (int, error) {
+	return len(body), nil
+}
@@ -0,0 +1,129 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// a handler like the ones in the api, which only answers get
+func handleTestThing(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if pathParam(r, "id") == "bad" {
+			writeError(w, r, newNotFoundError("unknown_thing", "", "Can't find thing bad"))
+			return
+		}
+		w.Header().Set("content-type", "text/plain")
+		w.Write([]byte("thing " + pathParam(r, "id")))
+		return
+	default:
+		return
+	}
+}
+
+func newTestRouter() *Router {
+	router := NewRouter(HandleRoot)
+	for _, prefix := range []string{"/corona/v1", "/corona/v2"} {
+		router.Handle(http.MethodGet, prefix+"/things/{id}", handleTestThing)
+		router.Handle(http.MethodDelete, prefix+"/things/{id}", handleTestThing)
+		router.Handle(http.MethodGet, prefix+"/badge/{id}.svg", handleTestThing)
+	}
+	return router
+}
+
+func serveTest(router http.Handler, method string, url string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, url, nil))
+	return recorder
+}
+
+func TestRouterPathParams(t *testing.T) {
+	router := newTestRouter()
+	tests := []struct {
+		url  string
+		code int
+		body string
+	}{
+		{"/corona/v1/things/a", http.StatusOK, "thing a"},
+		{"/corona/v1/things/a/", http.StatusOK, "thing a"}, // trailing slash is the same route
+		{"/corona/v1/badge/norway.svg", http.StatusOK, "thing norway"},
+		{"/corona/v1/badge/.svg", http.StatusBadRequest, "not valid url\n"}, // a parameter can't be empty
+		{"/corona/v1/things", http.StatusBadRequest, "not valid url\n"},
+		{"/corona/v1/things/a/b", http.StatusBadRequest, "not valid url\n"},
+	}
+	for _, test := range tests {
+		response := serveTest(router, http.MethodGet, test.url)
+		if response.Code != test.code || response.Body.String() != test.body {
+			t.Errorf("GET %s: got %d %q, want %d %q", test.url, response.Code, response.Body.String(), test.code, test.body)
+		}
+	}
+}
+
+func TestRouterHead(t *testing.T) {
+	router := newTestRouter()
+
+	response := serveTest(router, http.MethodHead, "/corona/v1/things/a")
+	if response.Code != http.StatusOK || response.Body.Len() != 0 || response.Header().Get("content-type") != "text/plain" {
+		t.Errorf("HEAD: got %d %q with content-type %q, want 200 without body and the get headers", response.Code, response.Body.String(), response.Header().Get("content-type"))
+	}
+
+	// errors are the same as for get
+	response = serveTest(router, http.MethodHead, "/corona/v2/things/bad")
+	if response.Code != http.StatusNotFound || response.Body.Len() != 0 {
+		t.Errorf("HEAD on a missing thing: got %d %q, want 404 without body", response.Code, response.Body.String())
+	}
+}
+
+func TestRouterHeadOnApiRoutes(t *testing.T) {
+	router := NewApiRouter()
+
+	response := serveTest(router, http.MethodHead, "/corona/v1/openapi.json")
+	if response.Code != http.StatusOK || response.Body.Len() != 0 || response.Header().Get("content-type") == "" {
+		t.Errorf("HEAD openapi.json: got %d with content-type %q and %d bytes, want 200 with headers and no body", response.Code, response.Header().Get("content-type"), response.Body.Len())
+	}
+
+	// the scope is checked before any extern api is asked
+	get := serveTest(router, http.MethodGet, "/corona/v1/country/Nowhere?scope=garbage")
+	head := serveTest(router, http.MethodHead, "/corona/v1/country/Nowhere?scope=garbage")
+	if get.Code != http.StatusBadRequest || head.Code != get.Code || head.Body.Len() != 0 {
+		t.Errorf("HEAD with an invalid scope: got %d, get got %d, want both 400", head.Code, get.Code)
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := newTestRouter()
+
+	response := serveTest(router, http.MethodPost, "/corona/v1/things/a")
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST v1: got %d, want 405", response.Code)
+	}
+	if allow := response.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("POST v1: got Allow %q, want DELETE, GET, HEAD, OPTIONS", allow)
+	}
+
+	response = serveTest(router, http.MethodPost, "/corona/v2/things/a")
+	var apiErr apiError
+	if err := json.Unmarshal(response.Body.Bytes(), &apiErr); err != nil {
+		t.Fatalf("POST v2: body %q is not json: %v", response.Body.String(), err)
+	}
+	if response.Code != http.StatusMethodNotAllowed || apiErr.Code != "method_not_allowed" || apiErr.Status != http.StatusMethodNotAllowed {
+		t.Errorf("POST v2: got %d %+v, want 405 method_not_allowed", response.Code, apiErr)
+	}
+	if allow := response.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("POST v2: got Allow %q, want DELETE, GET, HEAD, OPTIONS", allow)
+	}
+}
+
+func TestRouterOptions(t *testing.T) {
+	router := newTestRouter()
+
+	response := serveTest(router, http.MethodOptions, "/corona/v1/badge/norway.svg")
+	if response.Code != http.StatusNoContent || response.Body.Len() != 0 {
+		t.Errorf("OPTIONS: got %d %q, want 204 without body", response.Code, response.Body.String())
+	}
+	if allow := response.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("OPTIONS: got Allow %q, want GET, HEAD, OPTIONS", allow)
+	}
+}
@@ -0,0 +1,47 @@
+package CoronaAPI
+
+import (
+	"net/http"
+	"strings"
+)
+
+// makes the router with every route of the api, v1 routes marked v2 are also served under /corona/v2/
+func NewApiRouter() *Router {
+	routes := []struct {
+		method  string
+		pattern string
+		handler http.HandlerFunc
+		v2      bool // also served under /corona/v2/ with json errors
+	}{
+		{http.MethodGet, "/corona/v1/country/{country_name}", HandleCases, true},
+		{http.MethodGet, "/corona/v1/country/{country_name}/regions", HandleRegions, true},
+		{http.MethodGet, "/corona/v1/country/{country_name}/regions/{region_name}", HandleRegions, true},
+		{http.MethodGet, "/corona/v1/country/{country_name}/timeseries", HandleCasesTimeSeries, true},
+		{http.MethodGet, "/corona/v1/country/{country_name}/chart.svg", HandleChart, true},
+		{http.MethodGet, "/corona/v1/country/{country_name}/anomalies", HandleAnomalies, true},
+		{http.MethodGet, "/corona/v1/country/{country_name}/forecast", HandleForecast, true},
+		{http.MethodGet, "/corona/v1/continent/{continent_name}", HandleContinent, true},
+		{http.MethodGet, "/corona/v1/policy/{country_name}", HandleStringencyTrends, true},
+		{http.MethodGet, "/corona/v1/policy/{country_name}/timeseries", HandleStringencyTimeSeries, true},
+		{http.MethodGet, "/corona/v1/ranking", HandleRanking, true},
+		{http.MethodGet, "/corona/v1/badge/{country_name}.svg", HandleBadge, true},
+		{http.MethodGet, "/corona/v1/analysis/{country_name}/policy-impact", HandlePolicyImpact, true},
+		{http.MethodPost, "/corona/v1/batch", HandleBatch, true},
+		{http.MethodGet, "/corona/v1/notifications", HandleNotification, true},
+		{http.MethodPost, "/corona/v1/notifications", HandleNotification, true},
+		{http.MethodGet, "/corona/v1/notifications/{id}", HandleNotification, true},
+		{http.MethodDelete, "/corona/v1/notifications/{id}", HandleNotification, true},
+		{http.MethodGet, "/corona/v1/diag", HandleDiag, true},
+		{http.MethodGet, "/corona/v1/openapi.json", HandleOpenapi, false},
+		{http.MethodGet, "/corona/v1/docs", HandleDocs, false},
+		{http.MethodPost, "/corona/graphql", HandleGraphql, false},
+	}
+	router := NewRouter(HandleRoot) // HandleRoot answers urls without a route
+	for _, route := range routes {
+		router.Handle(route.method, route.pattern, route.handler)
+		if route.v2 {
+			router.Handle(route.method, strings.Replace(route.pattern, "/corona/v1/", "/corona/v2/", 1), route.handler)
+		}
+	}
+	return router
+}
@@ -0,0 +1,183 @@
+package CoronaAPI
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// first date with data in mmediagroup, used when the scope has an open start
+const firstDataDate = "2020-01-22"
+
+const dateLayout = "2006-01-02"
+
+var legacyScopeRegexp = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(\d{4}-\d{2}-\d{2})$`)
+var relativeScopeRegexp = regexp.MustCompile(`^last(\d+)([dwmy])$`)
+var durationRegexp = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?$`)
+var quarterRegexp = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+var isoWeekRegexp = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+var monthRegexp = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+var yearRegexp = regexp.MustCompile(`^(\d{4})$`)
+
+// example of valid scopes, added to every parse error
+const scopeExamples = "examples of valid scopes: 2020-12-01-2021-01-31, 2020-12-01/2021-01-31, 2021-01-01/, /2021-01-31, 2021-01-01/P2W, last30d, P2W, 2021-Q1, 2021-01, 2021-W05, 2021"
+
+// parses scope into a start and end date (YYYY-MM-DD), relative scopes end at the last complete day before now
+// both dates are in the scope, so durations and relative scopes like P2W or last14d cover 14 days
+func parseScope(scope string, now time.Time) (string, string, error) {
+	scope = strings.TrimSpace(scope)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastCompleteDay := today.AddDate(0, 0, -1)
+
+	var start, end time.Time
+	var err error
+	switch {
+	case scope == "":
+		return "", "", scopeError("scope is empty")
+	case legacyScopeRegexp.MatchString(scope): // 2020-12-01-2021-01-31
+		match := legacyScopeRegexp.FindStringSubmatch(scope)
+		if start, err = parseScopeDate(match[1], "start"); err != nil {
+			return "", "", err
+		}
+		if end, err = parseScopeDate(match[2], "end"); err != nil {
+			return "", "", err
+		}
+	case strings.Contains(scope, "/"): // ISO 8601 interval, ends may be open or a duration
+		parts := strings.Split(scope, "/")
+		if len(parts) != 2 {
+			return "", "", scopeError("interval \"" + scope + "\" should have exactly one '/'")
+		}
+		if parts[0] == "" && parts[1] == "" {
+			return "", "", scopeError("interval \"/\" needs at least a start or an end date")
+		}
+		if strings.HasPrefix(parts[0], "P") && strings.HasPrefix(parts[1], "P") {
+			return "", "", scopeError("interval \"" + scope + "\" can't have a duration at both ends")
+		}
+		if strings.HasPrefix(parts[1], "P") { // start/duration
+			if start, err = parseScopeDate(parts[0], "start"); err != nil {
+				return "", "", err
+			}
+			if end, err = addDuration(start, parts[1], 1); err != nil {
+				return "", "", err
+			}
+			end = end.AddDate(0, 0, -1)
+			break
+		}
+		if parts[1] == "" { // open end
+			end = lastCompleteDay
+		} else if end, err = parseScopeDate(parts[1], "end"); err != nil {
+			return "", "", err
+		}
+		if strings.HasPrefix(parts[0], "P") { // duration/end
+			if start, err = addDuration(end, parts[0], -1); err != nil {
+				return "", "", err
+			}
+			start = start.AddDate(0, 0, 1)
+		} else if parts[0] == "" { // open start
+			start, _ = time.Parse(dateLayout, firstDataDate)
+		} else if start, err = parseScopeDate(parts[0], "start"); err != nil {
+			return "", "", err
+		}
+	case relativeScopeRegexp.MatchString(scope): // last30d, last2w, last3m, last1y
+		match := relativeScopeRegexp.FindStringSubmatch(scope)
+		count, _ := strconv.Atoi(match[1])
+		if count < 1 {
+			return "", "", scopeError("relative scope \"" + scope + "\" must cover at least one unit")
+		}
+		end = lastCompleteDay
+		switch match[2] {
+		case "d":
+			start = end.AddDate(0, 0, -count)
+		case "w":
+			start = end.AddDate(0, 0, -7*count)
+		case "m":
+			start = end.AddDate(0, -count, 0)
+		case "y":
+			start = end.AddDate(-count, 0, 0)
+		}
+		start = start.AddDate(0, 0, 1)
+	case strings.HasPrefix(scope, "P"): // duration ending at the last complete day
+		end = lastCompleteDay
+		if start, err = addDuration(end, scope, -1); err != nil {
+			return "", "", err
+		}
+		start = start.AddDate(0, 0, 1)
+	case quarterRegexp.MatchString(scope): // 2021-Q1
+		match := quarterRegexp.FindStringSubmatch(scope)
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		start = time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 3, -1)
+	case isoWeekRegexp.MatchString(scope): // 2021-W05
+		match := isoWeekRegexp.FindStringSubmatch(scope)
+		year, _ := strconv.Atoi(match[1])
+		week, _ := strconv.Atoi(match[2])
+		if week < 1 || week > 53 {
+			return "", "", scopeError("week " + match[2] + " in \"" + scope + "\" is out of range (01-53)")
+		}
+		start = getIsoWeekStart(year, week)
+		if _, startWeek := start.ISOWeek(); startWeek != week {
+			return "", "", scopeError("year " + match[1] + " has no week " + match[2])
+		}
+		end = start.AddDate(0, 0, 6)
+	case monthRegexp.MatchString(scope): // 2021-01
+		match := monthRegexp.FindStringSubmatch(scope)
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		if month < 1 || month > 12 {
+			return "", "", scopeError("month " + match[2] + " in \"" + scope + "\" is out of range (01-12)")
+		}
+		start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, -1)
+	case yearRegexp.MatchString(scope): // 2021
+		year, _ := strconv.Atoi(scope)
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end = time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	default:
+		return "", "", scopeError("\"" + scope + "\" is not a recognised scope")
+	}
+
+	if end.Before(start) {
+		return "", "", scopeError("start date " + start.Format(dateLayout) + " is after end date " + end.Format(dateLayout))
+	}
+	return start.Format(dateLayout), end.Format(dateLayout), nil
+}
+
+// creates an error message for the scope with examples of valid scopes
+func scopeError(message string) error {
+	return newUnprocessableError("invalid_scope", "Invalid scope: "+message+". "+scopeExamples)
+}
+
+// parses a single date in the scope, which is the start or end date
+func parseScopeDate(date string, which string) (time.Time, error) {
+	parsed, err := time.Parse(dateLayout, date)
+	if err != nil {
+		if len(date) == len(dateLayout) {
+			return parsed, scopeError(which + " date \"" + date + "\" is not a real date")
+		}
+		return parsed, scopeError(which + " date \"" + date + "\" should be in the format YYYY-MM-DD")
+	}
+	return parsed, nil
+}
+
+// adds an ISO 8601 duration (PnYnMnWnD) to date, sign is 1 to go forward and -1 to go backward
+func addDuration(date time.Time, duration string, sign int) (time.Time, error) {
+	match := durationRegexp.FindStringSubmatch(duration)
+	if match == nil || duration == "P" {
+		return date, scopeError("duration \"" + duration + "\" should be in the format PnYnMnWnD, like P2W or P1M15D")
+	}
+	var values [4]int
+	for i := range values {
+		values[i], _ = strconv.Atoi(match[i+1]) // missing parts are 0
+	}
+	return date.AddDate(sign*values[0], sign*values[1], sign*(values[2]*7+values[3])), nil
+}
+
+// gets the monday of an ISO week
+func getIsoWeekStart(year int, week int) time.Time {
+	// january 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	daysSinceMonday := (int(jan4.Weekday()) + 6) % 7
+	return jan4.AddDate(0, 0, -daysSinceMonday+(week-1)*7)
+}
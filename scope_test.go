@@ -0,0 +1,86 @@
+package CoronaAPI
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScope(t *testing.T) {
+	now := time.Date(2021, time.March, 15, 13, 30, 0, 0, time.UTC) // last complete day is 2021-03-14
+
+	tests := []struct {
+		scope string
+		start string
+		end   string
+		err   string // part of the error message, empty if no error
+	}{
+		// legacy and ISO intervals
+		{scope: "2020-12-01-2021-01-31", start: "2020-12-01", end: "2021-01-31"},
+		{scope: "2020-12-01/2021-01-31", start: "2020-12-01", end: "2021-01-31"},
+		{scope: " 2020-12-01/2021-01-31 ", start: "2020-12-01", end: "2021-01-31"},
+		{scope: "2021-01-01/P2W", start: "2021-01-01", end: "2021-01-14"},
+		{scope: "P1M/2021-03-01", start: "2021-02-02", end: "2021-03-01"},
+
+		// open ends
+		{scope: "2021-01-01/", start: "2021-01-01", end: "2021-03-14"},
+		{scope: "/2021-01-31", start: firstDataDate, end: "2021-01-31"},
+
+		// relative ranges and durations, both ends are in the scope
+		{scope: "last1d", start: "2021-03-14", end: "2021-03-14"},
+		{scope: "P1D", start: "2021-03-14", end: "2021-03-14"},
+		{scope: "last30d", start: "2021-02-13", end: "2021-03-14"},
+		{scope: "last2w", start: "2021-03-01", end: "2021-03-14"},
+		{scope: "last1m", start: "2021-02-15", end: "2021-03-14"},
+		{scope: "last1y", start: "2020-03-15", end: "2021-03-14"},
+		{scope: "P2W", start: "2021-03-01", end: "2021-03-14"},
+		{scope: "P1M15D", start: "2021-01-31", end: "2021-03-14"},
+
+		// named periods
+		{scope: "2021-Q1", start: "2021-01-01", end: "2021-03-31"},
+		{scope: "2020-Q4", start: "2020-10-01", end: "2020-12-31"},
+		{scope: "2021-02", start: "2021-02-01", end: "2021-02-28"},
+		{scope: "2021-W05", start: "2021-02-01", end: "2021-02-07"},
+		{scope: "2020-W53", start: "2020-12-28", end: "2021-01-03"},
+		{scope: "2020", start: "2020-01-01", end: "2020-12-31"},
+
+		// errors
+		{scope: "", err: "scope is empty"},
+		{scope: "/", err: "needs at least a start or an end date"},
+		{scope: "2021-01-01/2021-02-01/2021-03-01", err: "should have exactly one '/'"},
+		{scope: "P1D/P2D", err: "can't have a duration at both ends"},
+		{scope: "2021-02-30/2021-03-01", err: `start date "2021-02-30" is not a real date`},
+		{scope: "2021-01-01/2021-2-1", err: `end date "2021-2-1" should be in the format YYYY-MM-DD`},
+		{scope: "2021-01-01/P2X", err: "should be in the format PnYnMnWnD"},
+		{scope: "2021-02-01/2021-01-01", err: "start date 2021-02-01 is after end date 2021-01-01"},
+		{scope: "last0d", err: "must cover at least one unit"},
+		{scope: "2021-13", err: "month 13 in \"2021-13\" is out of range (01-12)"},
+		{scope: "2021-W54", err: "week 54 in \"2021-W54\" is out of range (01-53)"},
+		{scope: "2021-W53", err: "year 2021 has no week 53"},
+		{scope: "yesterday", err: `"yesterday" is not a recognised scope`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scope, func(t *testing.T) {
+			start, end, err := parseScope(test.scope, now)
+			if test.err != "" {
+				if err == nil {
+					t.Fatalf("got %s to %s, want error containing %q", start, end, test.err)
+				}
+				if !strings.Contains(err.Error(), test.err) || !strings.Contains(err.Error(), scopeExamples) {
+					t.Fatalf("got error %q, want it to contain %q and the examples", err, test.err)
+				}
+				if status := getApiError(err).Status; status != 422 {
+					t.Fatalf("got status %d, want 422", status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if start != test.start || end != test.end {
+				t.Fatalf("got %s to %s, want %s to %s", start, end, test.start, test.end)
+			}
+		})
+	}
+}
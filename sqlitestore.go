@@ -0,0 +1,105 @@
+package CoronaAPI
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // sqlite driver for database/sql
+)
+
+// keeps webhooks in a sqlite file, for running the service without google cloud
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// opens the sqlite file at path, and makes the webhooks table if it's not there
+func newSqliteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1) // sqlite only has one writer at a time
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS webhooks (
+		id          TEXT PRIMARY KEY,
+		url         TEXT NOT NULL,
+		timeout     REAL NOT NULL,
+		time        TEXT NOT NULL,
+		field       TEXT NOT NULL,
+		country     TEXT NOT NULL,
+		trigger     TEXT NOT NULL,
+		occurrences REAL NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+const sqliteColumns = "id, url, timeout, time, field, country, trigger, occurrences"
+
+// reads a webhook from a row with sqliteColumns
+func scanWebhook(row interface{ Scan(...interface{}) error }) (WebhookRegistration, error) {
+	var webhook WebhookRegistration
+	var webhookTime string
+	err := row.Scan(&webhook.ID, &webhook.Url, &webhook.Timeout, &webhookTime, &webhook.Field, &webhook.Country, &webhook.Trigger, &webhook.Occurrences)
+	if err != nil {
+		return webhook, err
+	}
+	webhook.Time, err = time.Parse(time.RFC3339Nano, webhookTime)
+	return webhook, err
+}
+
+func (s *sqliteStore) Get(id string) (WebhookRegistration, error) {
+	webhook, err := scanWebhook(s.db.QueryRow("SELECT "+sqliteColumns+" FROM webhooks WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return webhook, newNotFoundError("unknown_webhook", "", "Can't find webhook with id "+id)
+	}
+	return webhook, err
+}
+
+func (s *sqliteStore) List() ([]WebhookRegistration, error) {
+	rows, err := s.db.Query("SELECT " + sqliteColumns + " FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var webhooks []WebhookRegistration
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return webhooks, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (s *sqliteStore) Add(webhook WebhookRegistration) (string, error) {
+	webhook.ID = newWebhookId()
+	_, err := s.db.Exec("INSERT INTO webhooks ("+sqliteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		webhook.ID, webhook.Url, webhook.Timeout, time.Now().UTC().Format(time.RFC3339Nano),
+		webhook.Field, webhook.Country, webhook.Trigger, webhook.Occurrences)
+	if err != nil {
+		return "", err
+	}
+	return webhook.ID, nil
+}
+
+func (s *sqliteStore) Update(id string, newTime time.Time, occurrences float64) error {
+	result, err := s.db.Exec("UPDATE webhooks SET time = ?, occurrences = ? WHERE id = ?",
+		newTime.UTC().Format(time.RFC3339Nano), occurrences, id)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return newNotFoundError("unknown_webhook", "", "Can't find webhook with id "+id)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Delete(id string) error {
+	_, err := s.db.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,274 @@
+package CoronaAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type CasesTimeSeries struct {
+	Country     string
+	Scope       string
+	Granularity string
+	Series      []CasesPoint
+	Metadata    *Metadata `json:",omitempty"`
+}
+
+type CasesPoint struct {
+	Date      string
+	New_cases int
+	Confirmed int
+	Recovered int
+}
+
+type StringencyTimeSeries struct {
+	Country     string
+	Scope       string
+	Granularity string
+	Series      []StringencyPoint
+	Metadata    *Metadata `json:",omitempty"`
+}
+
+type StringencyPoint struct {
+	Date       string
+	Stringency float64
+}
+
+type CovidTrackerRange struct {
+	Data map[string]map[string]Stringency `json:"data"`
+}
+
+// http://localhost:8080/corona/v1/country/{:country_name}/timeseries{?scope=begin_date-end_date&granularity=day|week|month}
+func HandleCasesTimeSeries(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName, startDate, endDate, granularity, err := getTimeSeriesUrlData(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resolution, err := getDateResolution(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response, err := getCasesTimeSeries(countryName, startDate, endDate, resolution, granularity)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// http://localhost:8080/corona/v1/policy/{:country_name}/timeseries{?scope=begin_date-end_date&granularity=day|week|month}
+func HandleStringencyTimeSeries(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// get request
+	case http.MethodGet:
+		countryName, startDate, endDate, granularity, err := getTimeSeriesUrlData(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response, err := getStringencyTimeSeries(countryName, startDate, endDate, granularity)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResponse(w, r, response)
+		return
+	default:
+		return
+	}
+}
+
+// gets country name, scope and granularity from a time series url
+func getTimeSeriesUrlData(r *http.Request) (string, string, string, string, error) {
+	countryName, startDate, endDate, err := getUrlData("country_name", r)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	granularity, err := getGranularity(r)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	return countryName, startDate, endDate, granularity, nil
+}
+
+// gets ?granularity, day if not in url
+func getGranularity(r *http.Request) (string, error) {
+	return parseGranularity(r.URL.Query().Get("granularity"))
+}
+
+// checks a granularity, day is used if it's empty
+func parseGranularity(granularity string) (string, error) {
+	switch granularity {
+	case "":
+		return "day", nil
+	case "day", "week", "month":
+		return granularity, nil
+	}
+	return "", errors.New("Invalid granularity '" + granularity + "', should be one of: day, week, month")
+}
+
+// gets the period a date belongs to, as ISO week (2021-W05) or month (2021-01)
+func getPeriod(date string, granularity string) string {
+	parsed, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	switch granularity {
+	case "week":
+		year, week := parsed.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return parsed.Format("2006-01")
+	}
+	return date
+}
+
+// gets the daily cases of a country in the scope, resampled to granularity
+func getCasesTimeSeries(countryName string, startDate string, endDate string, resolution string, granularity string) (CasesTimeSeries, error) {
+	var response CasesTimeSeries
+	confirmedData, err := getConfirmedData(countryName)
+	if err != nil {
+		return response, err
+	}
+	recoveredData, err := getRecoveredData(countryName, nil)
+	if err != nil {
+		return response, err
+	}
+	confirmedDates := getDates(confirmedData)
+	recoveredDates := getDates(recoveredData)
+	usedStart, usedEnd, err := resolveScope(confirmedDates, startDate, endDate, resolution)
+	if err != nil {
+		return response, err
+	}
+	if usedStart != "" {
+		if _, err := getDateValue(confirmedDates, usedStart); err != nil {
+			return response, err
+		}
+		if _, err := getDateValue(confirmedDates, usedEnd); err != nil {
+			return response, err
+		}
+	}
+
+	var daily []CasesPoint
+	var previous float64 = 0
+	for _, date := range getSortedDates(confirmedDates) {
+		confirmed, _ := confirmedDates[date].(float64)
+		recovered, _ := recoveredDates[date].(float64)
+		newCases := confirmed - previous
+		previous = confirmed
+		if usedStart != "" && (date < usedStart || date > usedEnd) {
+			continue
+		}
+		daily = append(daily, CasesPoint{Date: date, New_cases: int(newCases), Confirmed: int(confirmed), Recovered: int(recovered)})
+	}
+
+	response.Country = countryName
+	response.Scope = getScopeName(startDate, endDate)
+	response.Granularity = granularity
+	response.Series = resampleCases(daily, granularity)
+	dataDate := ""
+	if len(daily) > 0 {
+		dataDate = daily[len(daily)-1].Date
+	}
+	response.Metadata = newMetadata(dataDate, confirmedData.source, recoveredData.source)
+	return response, nil
+}
+
+// sums new cases per period and uses the values at the end of the period for cumulative data
+func resampleCases(daily []CasesPoint, granularity string) []CasesPoint {
+	if granularity == "day" {
+		return daily
+	}
+	var resampled []CasesPoint
+	for _, point := range daily {
+		period := getPeriod(point.Date, granularity)
+		if len(resampled) > 0 && resampled[len(resampled)-1].Date == period {
+			last := &resampled[len(resampled)-1]
+			last.New_cases += point.New_cases
+			last.Confirmed = point.Confirmed
+			last.Recovered = point.Recovered
+			continue
+		}
+		point.Date = period
+		resampled = append(resampled, point)
+	}
+	return resampled
+}
+
+// gets stringency of every day between start and end date from covidtracker, and where it came from
+func getStringencySeries(countryCode string, startDate string, endDate string) ([]StringencyPoint, Source, error) {
+	url := "https://covidtrackerapi.bsg.ox.ac.uk/api/v2/stringency/date-range/" + startDate + "/" + endDate
+	body, source, err := getCachedResponse(url)
+	if err != nil {
+		return nil, source, newUpstreamError("covidtracker", err, "reponse error from covidtracker (error with extern api)")
+	}
+	var dateRange CovidTrackerRange
+	json.Unmarshal(body, &dateRange)
+
+	var series []StringencyPoint
+	for date, countries := range dateRange.Data {
+		if data, ok := countries[countryCode]; ok {
+			series = append(series, StringencyPoint{Date: date, Stringency: data.Stringency})
+		}
+	}
+	if len(series) == 0 {
+		return nil, source, errors.New("No stringency data for " + countryCode + " between " + startDate + " and " + endDate)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Date < series[j].Date })
+	return series, source, nil
+}
+
+// gets the stringency of a country in the scope, resampled to granularity
+func getStringencyTimeSeries(countryName string, startDate string, endDate string, granularity string) (StringencyTimeSeries, error) {
+	var response StringencyTimeSeries
+	countryCode, codeSource, err := getCountryCodeByName(countryName)
+	if err != nil {
+		return response, err
+	}
+	if startDate == "" { // if not using scope, gets everything up to yesterday
+		startDate = firstDataDate
+		endDate = time.Now().AddDate(0, 0, -1).Format(dateLayout)
+	}
+	series, source, err := getStringencySeries(countryCode, startDate, endDate)
+	if err != nil {
+		return response, err
+	}
+
+	response.Country = countryName
+	response.Scope = getScopeName(startDate, endDate)
+	response.Granularity = granularity
+	response.Series = resampleStringency(series, granularity)
+	response.Metadata = newMetadata(series[len(series)-1].Date, codeSource, source)
+	return response, nil
+}
+
+// uses the stringency at the end of each period
+func resampleStringency(daily []StringencyPoint, granularity string) []StringencyPoint {
+	if granularity == "day" {
+		return daily
+	}
+	var resampled []StringencyPoint
+	for _, point := range daily {
+		point.Date = getPeriod(point.Date, granularity)
+		if len(resampled) > 0 && resampled[len(resampled)-1].Date == point.Date {
+			resampled[len(resampled)-1] = point
+			continue
+		}
+		resampled = append(resampled, point)
+	}
+	return resampled
+}
@@ -2,9 +2,8 @@ package CoronaAPI
 
 import (
 	"encoding/json"
-	"errors"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -14,68 +13,41 @@ type RestCountries struct {
 	Alpha3Code string `json:"alpha3Code"`
 }
 
-// gets data in url
-func getUrlData(endpointName string, r *http.Request) (string, string, string, error) {
-	parts := strings.Split(r.URL.Path, "/")
-
-	if len(parts) != 5 {
-		return "", "", "", errors.New("Wrong format, should be '/corona/v1/" + endpointName + "/{:country_name}{?scope=begin_date-end_date}'")
-	}
-	countryName := strings.Title(strings.ToLower(parts[4])) // makes all the letter lowercase, and then makes the first letter uppercase
-	scopeQuery := r.URL.Query().Get("scope")
-	var startDate, endDate = "", ""
-	var err error
-	if len(scopeQuery) > 0 {
-		startDate, endDate, err = getStartAndEndDate(scopeQuery)
-		if err != nil { // if error with queryl
-			return "", "", "", errors.New(err.Error())
-		}
+// gets data in url, paramName is the path parameter with the name
+func getUrlData(paramName string, r *http.Request) (string, string, string, error) {
+	countryName := strings.Title(strings.ToLower(pathParam(r, paramName))) // makes all the letter lowercase, and then makes the first letter uppercase
+	startDate, endDate, err := getUrlScope(r)
+	if err != nil { // if error with query
+		return "", "", "", err
 	}
 
 	return countryName, startDate, endDate, nil
 }
 
-// gets start and end date from date in url
-func getStartAndEndDate(dates string) (string, string, error) {
-	datesData := strings.Split(dates, "-")
-	if len(datesData) != 6 {
-		return "", "", errors.New("Wrong date format in scope. example of valid date: 2020-12-01-2021-01-31")
+// gets the start and end date of ?scope, both empty if not in url
+func getUrlScope(r *http.Request) (string, string, error) {
+	scopeQuery := r.URL.Query().Get("scope")
+	if len(scopeQuery) == 0 {
+		return "", "", nil
 	}
-	startDate := datesData[0] + "-" + datesData[1] + "-" + datesData[2]
-	endDate := datesData[3] + "-" + datesData[4] + "-" + datesData[5]
-	return startDate, endDate, nil
+	return parseScope(scopeQuery, time.Now())
 }
 
 // gets country code by country name with restcountries API
-func getCountryCodeByName(countryName string) (string, error) {
-	resp, err := http.Get("https://restcountries.eu/rest/v2/name/" + countryName + "?fullText=true")
+func getCountryCodeByName(countryName string) (string, Source, error) {
 	var restCountry []RestCountries
-	if err != nil {
-		return "", errors.New("reponse error from restcountries (error with extern api)")
+	body, source, err := getCachedResponse("https://restcountries.eu/rest/v2/name/" + url.PathEscape(countryName) + "?fullText=true")
+	if statusErr, ok := err.(statusCodeError); ok && statusErr.StatusCode == http.StatusNotFound {
+		err = nil // restcountries responds 404 on unknown names, handled below
 	}
-	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", errors.New("Error with ioutil.ReadAll (error with extern api)")
+		return "", source, newUpstreamError("restcountries", err, "reponse error from restcountries (error with extern api)")
 	}
-	json.Unmarshal([]byte(string(body)), &restCountry)
+	json.Unmarshal(body, &restCountry)
 	if len(restCountry) < 1 {
-		return "", errors.New("Can't find country with name: " + countryName)
+		return "", source, newNotFoundError("unknown_country", "restcountries", "Can't find country with name: "+countryName)
 	}
-	return restCountry[0].Alpha3Code, nil
-}
-
-// converts map to WebhookRegistration struct
-func mapToWebhookStruct(mapData map[string]interface{}, ID string) WebhookRegistration {
-	var newWebhook WebhookRegistration
-	newWebhook.ID = ID
-	newWebhook.Url = mapData["url"].(string)
-	newWebhook.Timeout = mapData["timeout"].(float64)
-	newWebhook.Field = mapData["field"].(string)
-	newWebhook.Country = mapData["country"].(string)
-	newWebhook.Trigger = mapData["trigger"].(string)
-	newWebhook.Occurrences = mapData["occurrences"].(float64)
-	newWebhook.Time = mapData["time"].(time.Time)
-	return newWebhook
+	return restCountry[0].Alpha3Code, source, nil
 }
 
 /*
@@ -2,18 +2,14 @@ package CoronaAPI
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
 	"time"
-
-	"cloud.google.com/go/firestore"
-	firebase "firebase.google.com/go"
-	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
 )
 
 type WebhookRegistration struct {
@@ -27,61 +23,67 @@ type WebhookRegistration struct {
 	Occurrences float64   `json:"occurrences"`
 }
 
-var ctx context.Context
-var client *firestore.Client
-
-// initialize firebase/firestore
-func InitFirebase() {
-	ctx = context.Background()
-	opt := option.WithCredentialsFile("./serviceAccountKey.json")
-	app, err := firebase.NewApp(ctx, nil, opt)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	client, err = app.Firestore(ctx)
-}
-
 // gets a single webhook data
 func getSingleWebhook(id string) (WebhookRegistration, error) {
-	data, err := client.Collection("webhooks").Doc(id).Get(ctx)
-	var webhook WebhookRegistration
-	if err != nil {
-		return webhook, errors.New("Can't find webhook with id " + id)
-	}
-	webhook = mapToWebhookStruct(data.Data(), data.Ref.ID)
-	return webhook, nil
+	return store.Get(id)
 }
 
 // deletes a single webhook
 func deleteSingleWebhook(id string) error {
-	_, err := client.Collection("webhooks").Doc(id).Delete(ctx)
-	if err != nil {
-		return err
+	return store.Delete(id)
+}
+
+// gets all webhooks that can be read, and an error for every stored webhook that can't
+func getWebhooks() ([]WebhookRegistration, []error, error) {
+	webhooks, err := store.List()
+	var invalid *invalidDocumentsError
+	if errors.As(err, &invalid) {
+		return webhooks, invalid.errs, nil
 	}
-	return nil
+	return webhooks, nil, err
 }
 
-// gets all webhooks
-func getWebhooks() ([]WebhookRegistration, error) {
-	iter := client.Collection("webhooks").Documents(ctx)
-	var allWebooks []WebhookRegistration
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
+// adds a webhook to the store with the current occurrences, returns its ID
+func addWebhook(webhookRegistration WebhookRegistration) (string, error) {
+	err := isValidData(webhookRegistration) // checks if the fields in body is valid
+	if err != nil {
+		return "", err
+	}
+	var occurrences float64 = 0.0
+	if webhookRegistration.Field == "stringency" { // if webhook for stringency
+		// gets country code
+		countryCode, _, err := getCountryCodeByName(webhookRegistration.Country)
+		if err != nil {
+			return "", err
 		}
+
+		currentDate := time.Now().Local()
+		tenDaysAgoDate := currentDate.AddDate(0, 0, -10).Format("2006-01-02") // calculates the date 10 days ago
+		stringencyData, err := getStringencyData(countryCode, tenDaysAgoDate)
+		if err != nil {
+			return "", err
+		}
+		occurrences = stringencyData.StringencyData.Stringency
+	} else { // if webhook for confirmed cases
+		confirmedData, err := getConfirmedData(webhookRegistration.Country)
 		if err != nil {
-			return allWebooks, err
+			return "", err
+		}
+		for _, v := range confirmedData.All["dates"].(map[string]interface{}) { // loops through confirmed dates and find the highest value
+			value := v.(float64)
+			if value > occurrences {
+				occurrences = value
+			}
 		}
-		webhook := mapToWebhookStruct(doc.Data(), doc.Ref.ID)
-		allWebooks = append(allWebooks, webhook)
 	}
-	return allWebooks, nil
+
+	webhookRegistration.Occurrences = occurrences
+	return store.Add(webhookRegistration)
 }
 
 // check if webhook data is valid
 func isValidData(webhookData WebhookRegistration) error {
-	if len(webhookData.Url) < 1 {
+	if !isValidUrl(webhookData.Url) {
 		return errors.New("Missing or invalid url data")
 	} else if webhookData.Timeout < 1 {
 		return errors.New("Missing or invalid timeout data")
@@ -95,70 +97,112 @@ func isValidData(webhookData WebhookRegistration) error {
 	return nil
 }
 
+// checks if a webhook url is an absolute http or https url, that a notification can be posted to
+func isValidUrl(webhookUrl string) bool {
+	parsed, err := url.ParseRequestURI(webhookUrl)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
 // webhook routine
 func WebhookRoutine() {
-	webhooks, err := getWebhooks()
-	if err != nil {
-		log.Fatalln(err)
+	webhooks, invalid, err := getWebhooks()
+	if err != nil { // tried again in the next run
+		log.Println("Error in getting webhooks:", err)
+	}
+	for _, err := range invalid { // the other webhooks are still notified
+		log.Println("Skipping webhook:", err)
 	}
 	currentDate := time.Now().Local()
 	tenDaysAgoDate := currentDate.AddDate(0, 0, -10).Format("2006-01-02") // gets the date 10 days ago
 
 	// for each webhook
 	for i := 0; i < len(webhooks); i++ {
-		currentTime := time.Now().Local()
-		var whenToNotificate time.Time = webhooks[i].Time.Add(time.Duration(webhooks[i].Timeout) * time.Minute) // gets the date for when to notificate
-		if webhooks[i].Field == "stringency" {                                                                  // if stringency webhook
-			// gets country code
-			countryCode, err := getCountryCodeByName(webhooks[i].Country)
-			if err != nil {
-				log.Fatalln(err)
-			}
+		if err := notifyWebhook(webhooks[i], tenDaysAgoDate); err != nil { // one webhook should not stop the others
+			log.Println("Skipping webhook", webhooks[i].ID+":", err)
+		}
+	}
+	time.Sleep(time.Duration(3600) * time.Second) // runs every hour
+	go WebhookRoutine()
+}
 
-			// gets stringency data
-			stringencyData, err := getStringencyData(countryCode, tenDaysAgoDate)
-			if err != nil {
-				log.Fatalln(err)
-			}
+// notifies a webhook if it's time to, stringency data is from tenDaysAgoDate
+func notifyWebhook(webhook WebhookRegistration, tenDaysAgoDate string) error {
+	currentTime := time.Now().Local()
+	var whenToNotificate time.Time = webhook.Time.Add(time.Duration(webhook.Timeout) * time.Minute) // gets the date for when to notificate
+	if webhook.Field == "stringency" {                                                              // if stringency webhook
+		// gets country code
+		countryCode, _, err := getCountryCodeByName(webhook.Country)
+		if err != nil {
+			return err
+		}
 
-			// check if it's time to notificate
-			if (stringencyData.StringencyData.Stringency != webhooks[i].Occurrences || webhooks[i].Trigger == "ON_TIMEOUT") && currentTime.After(whenToNotificate) {
-				webhooks[i].Occurrences = stringencyData.StringencyData.Stringency                   // so the newest data is in the notification
-				sendNotification(webhooks[i])                                                        // sends notification
-				updateWebhook(webhooks[i].ID, currentTime, stringencyData.StringencyData.Stringency) // update webhook in firestore
+		// gets stringency data
+		stringencyData, err := getStringencyData(countryCode, tenDaysAgoDate)
+		if err != nil {
+			return err
+		}
+
+		// check if it's time to notificate
+		if (stringencyData.StringencyData.Stringency != webhook.Occurrences || webhook.Trigger == "ON_TIMEOUT") && currentTime.After(whenToNotificate) {
+			webhook.Occurrences = stringencyData.StringencyData.Stringency // so the newest data is in the notification
+			// stringency data is used from 10 days ago, as covidtracker is behind, which the metadata shows
+			sendNotification(webhook, newMetadata(tenDaysAgoDate, stringencyData.source))
+			updateWebhook(webhook.ID, currentTime, stringencyData.StringencyData.Stringency) // update webhook in firestore
+		}
+	} else if webhook.Field == "confirmed" { // if confirmed webhook
+		confirmedData, err := getConfirmedData(webhook.Country)
+		if err != nil {
+			return err
+		}
+		dates, ok := confirmedData.All["dates"].(map[string]interface{})
+		if !ok {
+			return errors.New("No confirmed dates for " + webhook.Country)
+		}
+		// finds the highest occurrences of confirmed
+		var highestOccurrences float64 = 0.0
+		for _, v := range dates { // loops through confirmed dates and find the highest value
+			value, _ := v.(float64)
+			if value > highestOccurrences {
+				highestOccurrences = value
 			}
-		} else if webhooks[i].Field == "confirmed" { // if confirmed webhook
-			confirmedData, err := getConfirmedData(webhooks[i].Country)
+		}
+		// holds back ON_CHANGE when the change comes from a data dump or correction, if turned on
+		if webhook.Trigger == "ON_CHANGE" && os.Getenv("WEBHOOK_SUPPRESS_ANOMALIES") == "true" {
+			anomalous, err := isLatestDayAnomalous(webhook.Country)
 			if err != nil {
-				log.Fatalln(err)
-			}
-			// finds the highest occurrences of confirmed
-			var highestOccurrences float64 = 0.0
-			for _, v := range confirmedData.All["dates"].(map[string]interface{}) { // loops through confirmed dates and find the highest value
-				value := v.(float64)
-				if value > highestOccurrences {
-					highestOccurrences = value
-				}
-			}
-			// check if it's time to notificate
-			if (highestOccurrences != webhooks[i].Occurrences || webhooks[i].Trigger == "ON_TIMEOUT") && currentTime.After(whenToNotificate) {
-				webhooks[i].Occurrences = highestOccurrences                   // so the newest data is in the notification
-				sendNotification(webhooks[i])                                  // sends notification
-				updateWebhook(webhooks[i].ID, currentTime, highestOccurrences) // update webhook in firestore
+				log.Println("Error in anomaly detection: " + err.Error())
+			} else if anomalous {
+				// the dump is taken into the stored occurrences without a notification, so a later check
+				// doesn't send it either, the time is kept so ON_TIMEOUT is not moved
+				updateWebhook(webhook.ID, webhook.Time, highestOccurrences)
+				return nil
 			}
 		}
+		// check if it's time to notificate
+		if (highestOccurrences != webhook.Occurrences || webhook.Trigger == "ON_TIMEOUT") && currentTime.After(whenToNotificate) {
+			webhook.Occurrences = highestOccurrences // so the newest data is in the notification
+			sendNotification(webhook, newMetadata(getLatestDate(getDates(confirmedData)), confirmedData.source))
+			updateWebhook(webhook.ID, currentTime, highestOccurrences) // update webhook in firestore
+		}
 	}
-	time.Sleep(time.Duration(3600) * time.Second) // runs every hour
-	go WebhookRoutine()
+	return nil
 }
 
-// sends notification to client
-func sendNotification(webhook WebhookRegistration) {
-	json, err := json.Marshal(webhook)
+// sends notification to client, with where the data came from
+func sendNotification(webhook WebhookRegistration, metadata *Metadata) {
+	json, err := json.Marshal(struct {
+		WebhookRegistration
+		Metadata *Metadata
+	}{webhook, metadata})
 	if err != nil {
-		log.Fatalln("An error has occurred: %s", err)
+		log.Println("An error has occurred:", err)
+		return
 	}
 	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader([]byte(json))) // actualyl sends post request to klient
+	if err != nil {
+		log.Println("Error in HTTP request: " + err.Error())
+		return
+	}
 	req.Header.Add("content-type", "application/json")
 	client := http.Client{}
 	res, err := client.Do(req)
@@ -173,13 +217,14 @@ func sendNotification(webhook WebhookRegistration) {
 	}
 }
 
-// updates webhook in firestore
+// updates webhook in the store
 func updateWebhook(id string, newTime time.Time, newOccurences float64) {
-	_, err := client.Collection("webhooks").Doc(id).Set(ctx, map[string]interface{}{
-		"time":        newTime,
-		"occurrences": newOccurences,
-	}, firestore.MergeAll)
+	err := store.Update(id, newTime, newOccurences)
+	if err != nil && getApiError(err).Status == http.StatusNotFound { // deleted while it was notified
+		log.Println("Skipping update of webhook", id+":", err)
+		return
+	}
 	if err != nil {
-		log.Fatalln("An error has occurred: %s", err)
+		log.Println("An error has occurred:", err)
 	}
 }
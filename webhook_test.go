@@ -0,0 +1,44 @@
+package CoronaAPI
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookUrl(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://localhost:8081/hook?key=a", true},
+		{"", false},
+		{"::", false},
+		{"example.com/hook", false},
+		{"/hook", false},
+		{"ftp://example.com/hook", false},
+		{"https:///hook", false},
+	}
+	for _, test := range tests {
+		webhook := WebhookRegistration{Url: test.url, Timeout: 60, Field: "confirmed", Country: "Norway", Trigger: "ON_CHANGE"}
+		if err := isValidData(webhook); (err == nil) != test.valid {
+			t.Errorf("url %q: got %v, want valid %v", test.url, err, test.valid)
+		}
+	}
+}
+
+func TestPostWebhookWithInvalidUrl(t *testing.T) {
+	store = newMemoryStore()
+	body := `{"url": "::", "timeout": 60, "field": "confirmed", "country": "Norway", "trigger": "ON_CHANGE"}`
+	request := httptest.NewRequest(http.MethodPost, "/corona/v1/notifications", strings.NewReader(body))
+	response := httptest.NewRecorder()
+	NewApiRouter().ServeHTTP(response, request)
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "invalid url") {
+		t.Errorf("got %d %q, want 400 for the url", response.Code, response.Body.String())
+	}
+	if webhooks, _ := store.List(); len(webhooks) != 0 {
+		t.Errorf("got %d webhooks, want none added", len(webhooks))
+	}
+}
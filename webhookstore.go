@@ -0,0 +1,112 @@
+package CoronaAPI
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// where webhooks are kept, selected with WEBHOOK_STORE
+type WebhookStore interface {
+	Get(id string) (WebhookRegistration, error)      // not found error if there is no webhook with id
+	List() ([]WebhookRegistration, error)            // with an invalidDocumentsError and the other webhooks if some can't be read
+	Add(webhook WebhookRegistration) (string, error) // stores the webhook with the current time, returns its new id
+	Update(id string, newTime time.Time, occurrences float64) error
+	Delete(id string) error // no error if the webhook does not exist
+}
+
+var store WebhookStore
+
+// sets up the webhook store from WEBHOOK_STORE, which is firestore (default), sqlite or memory
+// the server stops if the store can't be set up, e.g. firestore without an emulator or credentials
+func InitWebhookStore() {
+	var err error
+	switch storeName := os.Getenv("WEBHOOK_STORE"); storeName {
+	case "", "firestore":
+		store, err = newFirestoreStore()
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "./webhooks.db"
+		}
+		store, err = newSqliteStore(path)
+	case "memory":
+		store = newMemoryStore()
+	default:
+		log.Fatalln("Invalid WEBHOOK_STORE '" + storeName + "', should be one of: firestore, sqlite, memory")
+	}
+	if err != nil {
+		log.Fatalln("Can't start the webhook store:", err)
+	}
+}
+
+// makes an id like the ones firestore makes
+func newWebhookId() string {
+	bytes := make([]byte, 10)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// keeps webhooks in memory, they are gone when the server stops
+type memoryStore struct {
+	mutex    sync.Mutex
+	webhooks map[string]WebhookRegistration
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{webhooks: map[string]WebhookRegistration{}}
+}
+
+func (m *memoryStore) Get(id string) (WebhookRegistration, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return webhook, newNotFoundError("unknown_webhook", "", "Can't find webhook with id "+id)
+	}
+	return webhook, nil
+}
+
+func (m *memoryStore) List() ([]WebhookRegistration, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var webhooks []WebhookRegistration
+	for _, webhook := range m.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID }) // same order as firestore
+	return webhooks, nil
+}
+
+func (m *memoryStore) Add(webhook WebhookRegistration) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	webhook.ID = newWebhookId()
+	webhook.Time = time.Now()
+	m.webhooks[webhook.ID] = webhook
+	return webhook.ID, nil
+}
+
+func (m *memoryStore) Update(id string, newTime time.Time, occurrences float64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return newNotFoundError("unknown_webhook", "", "Can't find webhook with id "+id)
+	}
+	webhook.Time = newTime
+	webhook.Occurrences = occurrences
+	m.webhooks[id] = webhook
+	return nil
+}
+
+func (m *memoryStore) Delete(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.webhooks, id)
+	return nil
+}
@@ -0,0 +1,110 @@
+package CoronaAPI
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// the behaviour every webhook store must have, run against each store
+func testWebhookStore(t *testing.T, store WebhookStore) {
+	isNotFound := func(err error) bool {
+		return err != nil && getApiError(err).Status == http.StatusNotFound && getApiError(err).Code == "unknown_webhook"
+	}
+
+	webhooks, err := store.List()
+	if err != nil || len(webhooks) != 0 {
+		t.Fatalf("List on an empty store: got %v %v, want no webhooks", webhooks, err)
+	}
+	if _, err := store.Get("missing"); !isNotFound(err) {
+		t.Errorf("Get missing: got %v, want unknown_webhook 404", err)
+	}
+	if err := store.Update("missing", time.Now(), 1); !isNotFound(err) {
+		t.Errorf("Update missing: got %v, want unknown_webhook 404", err)
+	}
+	if err := store.Delete("missing"); err != nil {
+		t.Errorf("Delete missing: got %v, want no error", err)
+	}
+	if webhooks, _ := store.List(); len(webhooks) != 0 {
+		t.Errorf("Update and Delete of a missing webhook made %d webhooks", len(webhooks))
+	}
+
+	before := time.Now().Add(-time.Second)
+	webhook := WebhookRegistration{Url: "https://example.com/hook", Timeout: 3600, Field: "confirmed", Country: "Norway", Trigger: "ON_CHANGE", Occurrences: 12}
+	firstId, err := store.Add(webhook)
+	if err != nil || firstId == "" {
+		t.Fatalf("Add: got %q %v, want an id", firstId, err)
+	}
+	secondId, err := store.Add(WebhookRegistration{Url: "https://example.com/other", Timeout: 60, Field: "stringency", Country: "Sweden", Trigger: "ON_TIMEOUT"})
+	if err != nil || secondId == firstId {
+		t.Fatalf("Add: got %q %v, want a new id", secondId, err)
+	}
+
+	stored, err := store.Get(firstId)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Time.Before(before) || stored.Time.After(time.Now().Add(time.Second)) {
+		t.Errorf("Get: got time %v, want the time it was added", stored.Time)
+	}
+	webhook.ID = firstId
+	webhook.Time = stored.Time
+	if stored != webhook {
+		t.Errorf("Get: got %+v, want %+v", stored, webhook)
+	}
+
+	webhooks, err = store.List()
+	if err != nil || len(webhooks) != 2 || webhooks[0].ID > webhooks[1].ID {
+		t.Fatalf("List: got %+v %v, want both webhooks sorted by id", webhooks, err)
+	}
+
+	updated := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.Update(firstId, updated, 20); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	stored, err = store.Get(firstId)
+	if err != nil || !stored.Time.Equal(updated) || stored.Occurrences != 20 || stored.Url != webhook.Url {
+		t.Errorf("Get after Update: got %+v %v, want the new time and occurrences and the other fields kept", stored, err)
+	}
+
+	if err := store.Delete(firstId); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(firstId); !isNotFound(err) {
+		t.Errorf("Get after Delete: got %v, want unknown_webhook 404", err)
+	}
+	if err := store.Update(firstId, updated, 30); !isNotFound(err) {
+		t.Errorf("Update after Delete: got %v, want unknown_webhook 404", err)
+	}
+	webhooks, err = store.List()
+	if err != nil || len(webhooks) != 1 || webhooks[0].ID != secondId {
+		t.Errorf("List after Delete: got %+v %v, want only %s", webhooks, err, secondId)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testWebhookStore(t, newMemoryStore())
+}
+
+func TestSqliteStore(t *testing.T) {
+	store, err := newSqliteStore(filepath.Join(t.TempDir(), "webhooks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.db.Close()
+	testWebhookStore(t, store)
+}
+
+// only runs against the firestore emulator, the webhooks collection must be empty
+func TestFirestoreStore(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	store, err := newFirestoreStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testWebhookStore(t, store)
+}